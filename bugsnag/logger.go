@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	bugsnagGo "github.com/bugsnag/bugsnag-go/v2"
 	goerrors "github.com/go-errors/errors"
 
 	"github.com/octavore/nagax/logger"
@@ -14,39 +15,95 @@ import (
 type bugsnagLogger struct {
 	logger.Logger
 	Notify func(error, ...any) // Notify is the module.Notify which wraps bugsnag.Notify with added print
+	Allow  func(error) bool    // Allow is logger.Module.AllowError, which limits repeated errors
 }
 
 func (b *bugsnagLogger) Error(args ...any) {
 	if len(args) == 1 {
 		if originalErr, ok := args[0].(error); ok {
 			// this handles case with only one error arugment
-			var req *http.Request
-			if re, ok := originalErr.(GetRequestable); ok {
-				req = re.GetRequest()
-			}
-			if err, ok := originalErr.(*goerrors.Error); ok {
-				if re, ok := err.Err.(GetRequestable); ok && re.GetRequest() != nil {
-					req = re.GetRequest()
-				}
-			} else {
-				originalErr = errors.WrapS(originalErr, 1)
-			}
-			if req != nil {
-				b.Notify(originalErr, req)
-			} else {
-				b.Notify(originalErr)
-			}
+			b.notifyError(originalErr)
 			return
 		}
 	}
 	b.Notify(errors.New(fmt.Sprint(args...)))
 }
 
+// notifyError notifies bugsnag of originalErr, adding the request if available
+func (b *bugsnagLogger) notifyError(originalErr error, rawData ...any) {
+	if b.Allow != nil && !b.Allow(originalErr) {
+		return
+	}
+	var req *http.Request
+	if re, ok := originalErr.(GetRequestable); ok {
+		req = re.GetRequest()
+	}
+	if err, ok := originalErr.(*goerrors.Error); ok {
+		if re, ok := err.Err.(GetRequestable); ok && re.GetRequest() != nil {
+			req = re.GetRequest()
+		}
+	} else {
+		originalErr = errors.WrapS(originalErr, 2)
+	}
+	if req != nil {
+		rawData = append(rawData, req)
+	}
+	b.Notify(originalErr, rawData...)
+}
+
 func (b *bugsnagLogger) Errorf(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
 	b.Error(msg)
 }
 
+// ErrorCtx is like Error, adding the logger name and fields of ctx as metadata
 func (b *bugsnagLogger) ErrorCtx(ctx context.Context, args ...any) {
-	b.Error(args...)
+	fields := logger.Fields(ctx)
+	if len(fields) == 0 && logger.Name(ctx) == "" {
+		b.Error(args...)
+		return
+	}
+	metadata := logMetadata(ctx, "", fields)
+	if len(args) == 1 {
+		if originalErr, ok := args[0].(error); ok {
+			b.notifyError(originalErr, metadata)
+			return
+		}
+	}
+	b.Notify(errors.New(fmt.Sprint(args...)), metadata)
+}
+
+// ErrorKV notifies bugsnag with the key-value pairs and the fields of ctx as
+// metadata. The first error value is reported as the error, otherwise msg is.
+func (b *bugsnagLogger) ErrorKV(ctx context.Context, msg string, kv ...any) {
+	var err error
+	for i := 1; i < len(kv) && err == nil; i += 2 {
+		err, _ = kv[i].(error)
+	}
+	metadata := logMetadata(ctx, msg, append(logger.Fields(ctx), kv...))
+	if err == nil {
+		b.Notify(errors.New(msg), metadata)
+		return
+	}
+	b.notifyError(err, metadata)
+}
+
+// logMetadata returns the logger name, message and key-value pairs as the
+// "log" tab
+func logMetadata(ctx context.Context, msg string, kv []any) bugsnagGo.MetaData {
+	fields := map[string]any{}
+	if name := logger.Name(ctx); name != "" {
+		fields["logger"] = name
+	}
+	if msg != "" {
+		fields["message"] = msg
+	}
+	for i := 0; i < len(kv); i += 2 {
+		var v any = "!MISSING"
+		if i+1 < len(kv) {
+			v = kv[i+1]
+		}
+		fields[fmt.Sprint(kv[i])] = fmt.Sprint(v)
+	}
+	return bugsnagGo.MetaData{"log": fields}
 }
@@ -18,7 +18,7 @@ import (
 
 type Config struct {
 	Bugsnag *struct {
-		APIKey string `json:"api_key"`
+		APIKey string `json:"api_key" validate:"required"`
 	} `json:"bugsnag"`
 }
 
@@ -57,7 +57,11 @@ func (m *Module) Init(c *service.Config) {
 			m.bugsnagEnabled = true
 		}
 
-		m.Logger.Logger = &bugsnagLogger{Logger: m.Logger.Logger, Notify: m.Notify}
+		m.Logger.Logger = &bugsnagLogger{
+			Logger: m.Logger.Logger,
+			Notify: m.Notify,
+			Allow:  m.Logger.AllowError,
+		}
 		return nil
 	}
 }
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/octavore/naga/service"
 )
@@ -19,9 +22,72 @@ func (m *Module) registerCommands(c *service.Config) {
 	c.AddCommand(&service.Command{
 		Keyword: "config:print",
 		Run: func(ctx *service.CommandContext) {
-			fmt.Println(string(m.Byte))
+			b := maskSecrets(m.Byte)
+			if f := ctx.Flags["format"]; f.Present() {
+				var err error
+				b, err = encodeFormat(*f.Value, b)
+				if err != nil {
+					ctx.Fatal("error: %v", err)
+				}
+			}
+			fmt.Println(strings.TrimSpace(string(b)))
+			m.printSources()
 		},
 		ShortUsage: "Print current config.json",
-		Usage:      "",
+		Usage:      "Print the merged config with secrets masked, followed by the files each top-level key was read from (on stderr).",
+		Flags: []*service.Flag{{
+			Key:   "format",
+			Usage: "convert the config to json, yaml or toml",
+		}},
+	})
+
+	c.AddCommand(&service.Command{
+		Keyword: "config:env",
+		Run: func(ctx *service.CommandContext) {
+			m.printEnv()
+		},
+		ShortUsage: "List env variables read by the app",
+		Usage:      "List env variables read via the Getenv methods, with their current values (secrets masked) and where they are read.",
+	})
+
+	c.AddCommand(&service.Command{
+		Keyword: "config:schema",
+		Run: func(ctx *service.CommandContext) {
+			b, err := m.JSONSchema()
+			if err != nil {
+				ctx.Fatal("error: %v", err)
+			}
+			fmt.Println(string(b))
+		},
+		ShortUsage: "Print JSON Schema for config.json",
+		Usage:      "Print a JSON Schema (draft 2020-12) for the config of every module, for validation and autocomplete in editors.",
 	})
+
+	c.AddCommand(&service.Command{
+		Keyword: "config:validate",
+		Run: func(ctx *service.CommandContext) {
+			ok := len(m.validationErrs) == 0
+			for _, err := range m.validationErrs {
+				fmt.Println(err)
+			}
+			if m.Strict {
+				if err := m.checkStrict(); err != nil {
+					fmt.Println(err)
+					ok = false
+				}
+			}
+			if !ok {
+				os.Exit(1)
+			}
+			fmt.Printf("config: %d config struct(s) ok\n", len(m.configDefs))
+		},
+		ShortUsage: "Validate config.json",
+		Usage:      "Check the config of every module against its `validate` struct tags, exiting non-zero if invalid.",
+	})
+}
+
+// isCommand returns true if the app was run with the given command keyword
+func isCommand(keyword string) bool {
+	args := flag.Args()
+	return len(args) > 0 && args[0] == keyword
 }
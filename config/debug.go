@@ -6,39 +6,57 @@ import (
 	"strings"
 )
 
-// PrintConsolidatedConfig prints out the definitions of all config
+// PrintConsolidatedConfig prints out the definitions of all config, along with
+// the default value and the env variable which overrides each field.
 func (m *Module) PrintConsolidatedConfig() {
 	for _, typ := range m.configDefs {
 		if typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct {
 			typ = typ.Elem()
 		}
-		m.printFieldsWithTags(typ, 0)
+		fmt.Printf("[%s]\n", typ)
+		m.printFieldsWithTags(typ, 0, EnvPrefix)
+		fmt.Println()
 	}
 }
 
-func (m *Module) printFieldsWithTags(typ reflect.Type, indent int) {
-	if indent == 0 {
-		fmt.Printf("[%s]\n", typ)
-	}
+func (m *Module) printFieldsWithTags(typ reflect.Type, indent int, env string) {
 	prefix := strings.Repeat(" ", indent)
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		fieldName := field.Tag.Get("json")
-		if fieldName == "" {
-			fieldName = field.Name
-		}
+	for _, field := range configFields(typ) {
+		fieldName := field.Name
+		fieldEnv := envKey(env, fieldName)
 		fieldTyp := field.Type
 		if fieldTyp.Kind() == reflect.Ptr && fieldTyp.Elem().Kind() == reflect.Struct {
 			fieldTyp = fieldTyp.Elem()
 		}
 		if fieldTyp.Kind() == reflect.Struct {
 			fmt.Printf("%s%s:\n", prefix, fieldName)
-			m.printFieldsWithTags(fieldTyp, indent+4)
-			return
+			m.printFieldsWithTags(fieldTyp, indent+4, fieldEnv)
+			continue
+		}
+		fmt.Printf("%s%s: %s%s%s\n", prefix, fieldName, goTypeToStr(field.Type), defaultHint(field), envHint(fieldEnv))
+
+		// describe the fields of map values using a placeholder key
+		elemTyp := indirectType(fieldTyp)
+		if elemTyp.Kind() == reflect.Map && indirectType(elemTyp.Elem()).Kind() == reflect.Struct {
+			fmt.Printf("%s    <key>:\n", prefix)
+			m.printFieldsWithTags(indirectType(elemTyp.Elem()), indent+8, fieldEnv+"_<KEY>")
 		}
-		fmt.Printf("%s%s: %s\n", prefix, fieldName, goTypeToStr(field.Type))
 	}
-	fmt.Println()
+}
+
+func defaultHint(field configField) string {
+	d, ok := field.Tag.Lookup("default")
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" (default: %q)", d)
+}
+
+func envHint(env string) string {
+	if EnvPrefix == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (env: %s)", env)
 }
 
 func goTypeToStr(t reflect.Type) string {
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// applyDefaults sets zero fields of i (a pointer) to the value of their
+// `default` struct tag
+func applyDefaults(i any) error {
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	return fillDefaults(v.Elem(), "")
+}
+
+// fillDefaults sets zero fields of v to their defaults, and recurses into
+// nested structs
+func fillDefaults(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return fillDefaults(v.Elem(), path)
+
+	case reflect.Struct:
+		for _, f := range configFields(v.Type()) {
+			fv := v.FieldByIndex(f.Index)
+			if !fv.CanSet() {
+				continue
+			}
+			fieldPath := joinPath(path, f.Name)
+			if d, ok := f.Tag.Lookup("default"); ok && fv.IsZero() {
+				err := setFromString(fv, d)
+				if err != nil {
+					return fmt.Errorf("config: invalid default for %s: %w", fieldPath, err)
+				}
+			}
+			err := fillDefaults(fv, fieldPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	jsonNull            = []byte("null")
+)
+
+// decodeWithDefaults decodes data into v like json.Unmarshal, except that
+// structs allocated for pointers, map values and slice elements have their
+// defaults applied before data is decoded into them. Explicit values in data,
+// including zero values, therefore always win over defaults. Durations may
+// also be strings such as "10s", like in `default` tags and env variables.
+func decodeWithDefaults(v reflect.Value, data []byte, path string) error {
+	if v.Type() == durationType && v.CanSet() {
+		// durations are strings such as "10s", or integer nanoseconds
+		var s string
+		if json.Unmarshal(data, &s) == nil {
+			err := setFromString(v, s)
+			if err != nil {
+				return fmt.Errorf("config: invalid duration for %s: %w", path, err)
+			}
+			return nil
+		}
+	}
+	if !v.CanAddr() || !walkJSON(v.Type()) || bytes.Equal(bytes.TrimSpace(data), jsonNull) {
+		return unmarshalValue(v, data)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := v
+		if v.IsNil() {
+			elem = reflect.New(v.Type().Elem())
+			err := fillDefaults(elem, path)
+			if err != nil {
+				return err
+			}
+		}
+		err := decodeWithDefaults(elem.Elem(), data, path)
+		if err != nil {
+			return err
+		}
+		v.Set(elem)
+
+	case reflect.Struct:
+		obj := map[string]json.RawMessage{}
+		if json.Unmarshal(data, &obj) != nil {
+			return unmarshalValue(v, data) // for the json error
+		}
+		for _, k := range rawKeys(obj) {
+			f, ok := lookupField(v.Type(), k)
+			if !ok {
+				continue
+			}
+			err := decodeWithDefaults(v.FieldByIndex(f.Index), obj[k], joinPath(path, f.Name))
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		obj := map[string]json.RawMessage{}
+		if json.Unmarshal(data, &obj) != nil {
+			return unmarshalValue(v, data)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(obj)))
+		}
+		for _, k := range rawKeys(obj) {
+			elem, err := newElem(v.Type().Elem(), obj[k], joinPath(path, k))
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), elem)
+		}
+
+	case reflect.Slice:
+		arr := []json.RawMessage{}
+		if json.Unmarshal(data, &arr) != nil {
+			return unmarshalValue(v, data)
+		}
+		s := reflect.MakeSlice(v.Type(), len(arr), len(arr))
+		for i, raw := range arr {
+			elem, err := newElem(v.Type().Elem(), raw, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+			s.Index(i).Set(elem)
+		}
+		v.Set(s)
+	}
+	return nil
+}
+
+// newElem returns a new value of typ with its defaults applied and data
+// decoded into it
+func newElem(typ reflect.Type, data []byte, path string) (reflect.Value, error) {
+	elem := reflect.New(typ).Elem()
+	err := fillDefaults(elem, path)
+	if err != nil {
+		return elem, err
+	}
+	return elem, decodeWithDefaults(elem, data, path)
+}
+
+// walkJSON returns true if decodeWithDefaults decodes values of typ itself,
+// rather than leaving them to encoding/json
+func walkJSON(typ reflect.Type) bool {
+	if reflect.PointerTo(typ).Implements(jsonUnmarshalerType) ||
+		reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() != reflect.Uint8 // []byte is base64
+	case reflect.Map:
+		return typ.Key().Kind() == reflect.String
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			// embedded struct pointers and ,string fields are left to encoding/json
+			if f.Anonymous && f.Type.Kind() == reflect.Ptr && f.Tag.Get("json") == "" {
+				return false
+			}
+			if _, opts, _ := strings.Cut(f.Tag.Get("json"), ","); strings.Contains(opts, "string") {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// rawKeys returns the keys of the json object obj in a stable order
+func rawKeys(obj map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unmarshalValue decodes data into v with encoding/json
+func unmarshalValue(v reflect.Value, data []byte) error {
+	if !v.CanAddr() {
+		return fmt.Errorf("config: cannot decode into %s", v.Type())
+	}
+	return json.Unmarshal(data, v.Addr().Interface())
+}
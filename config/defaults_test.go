@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+type testDefaultsConfig struct {
+	Port     int           `json:"port" default:"8000"`
+	Validity time.Duration `json:"validity" default:"12h"`
+	Hosts    []string      `json:"hosts" default:"a.com,b.com"`
+	Slack    struct {
+		Channel string `json:"channel" default:"#activity"`
+	} `json:"slack"`
+	Bugsnag *struct {
+		Stage string `json:"stage" default:"production"`
+	} `json:"bugsnag"`
+	Datasources map[string]struct {
+		Driver string `json:"driver" default:"postgres"`
+	} `json:"datasources"`
+}
+
+func TestReadConfigDefaults(t *testing.T) {
+	m := &Module{Byte: []byte(`{}`)}
+	cfg := &testDefaultsConfig{}
+	must.NoError(t, m.ReadConfig(cfg))
+	test.Eq(t, 8000, cfg.Port)
+	test.Eq(t, 12*time.Hour, cfg.Validity)
+	test.Eq(t, []string{"a.com", "b.com"}, cfg.Hosts)
+	test.Eq(t, "#activity", cfg.Slack.Channel)
+	test.Nil(t, cfg.Bugsnag)
+
+	m = &Module{Byte: []byte(`{
+		"port": 0,
+		"slack": {"channel": "#general"},
+		"bugsnag": {},
+		"datasources": {"production": {}, "test": {"driver": "sqlite"}}
+	}`)}
+	cfg = &testDefaultsConfig{}
+	must.NoError(t, m.ReadConfig(cfg))
+	test.Eq(t, 0, cfg.Port) // explicit values override defaults
+	test.Eq(t, "#general", cfg.Slack.Channel)
+	must.NotNil(t, cfg.Bugsnag)
+	test.Eq(t, "production", cfg.Bugsnag.Stage)
+	test.Eq(t, "postgres", cfg.Datasources["production"].Driver)
+	test.Eq(t, "sqlite", cfg.Datasources["test"].Driver)
+}
+
+func TestReadConfigDefaultsExplicitZero(t *testing.T) {
+	m := &Module{Byte: []byte(`{
+		"bugsnag": {"stage": ""},
+		"datasources": {"production": {"driver": ""}}
+	}`)}
+	cfg := &testDefaultsConfig{}
+	must.NoError(t, m.ReadConfig(cfg))
+	must.NotNil(t, cfg.Bugsnag)
+	test.Eq(t, "", cfg.Bugsnag.Stage)
+	test.Eq(t, "", cfg.Datasources["production"].Driver)
+
+	items := &struct {
+		Items []struct {
+			Port int `json:"port" default:"80"`
+		} `json:"items"`
+	}{}
+	m = &Module{Byte: []byte(`{"items": [{}, {"port": 0}]}`)}
+	must.NoError(t, m.ReadConfig(items))
+	must.Len(t, 2, items.Items)
+	test.Eq(t, 80, items.Items[0].Port)
+	test.Eq(t, 0, items.Items[1].Port)
+}
+
+func TestReadConfigInvalidDefault(t *testing.T) {
+	m := &Module{Byte: []byte(`{}`)}
+	err := m.ReadConfig(&struct {
+		Port int `json:"port" default:"eighty"`
+	}{})
+	test.ErrorContains(t, err, "config: invalid default for port")
+}
+
+func TestReadConfigDurationStrings(t *testing.T) {
+	cfg := &struct {
+		Validity time.Duration            `json:"validity" default:"12h"`
+		Timeouts map[string]time.Duration `json:"timeouts"`
+	}{}
+	m := &Module{Byte: []byte(`{"validity": "5m", "timeouts": {"read": "10s", "write": 1000000000}}`)}
+	must.NoError(t, m.ReadConfig(cfg))
+	test.Eq(t, 5*time.Minute, cfg.Validity)
+	test.Eq(t, map[string]time.Duration{"read": 10 * time.Second, "write": time.Second}, cfg.Timeouts)
+
+	m = &Module{Byte: []byte(`{"validity": "5 minutes"}`)}
+	err := m.ReadConfig(cfg)
+	test.ErrorContains(t, err, "config: invalid duration for validity")
+}
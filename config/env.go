@@ -0,0 +1,265 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of env variables which override config values,
+// e.g. APP_PORT overrides {"port": ...}. Set to "" to disable the overlay.
+// To use a different prefix, change this in an init block in your app.
+var EnvPrefix = "APP"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// envName converts a json key into its env variable form
+func envName(key string) string {
+	key = strings.ToUpper(key)
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// envKey joins parts of a json path into an env variable name
+func envKey(prefix string, key string) string {
+	if prefix == "" {
+		return envName(key)
+	}
+	return prefix + "_" + envName(key)
+}
+
+// applyEnvOverlay overrides fields of i (a pointer) with values from env variables
+// prefixed with EnvPrefix, with names derived from the json path of each field.
+func applyEnvOverlay(i any) error {
+	if EnvPrefix == "" {
+		return nil
+	}
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	return overlayValue(v.Elem(), EnvPrefix)
+}
+
+func overlayValue(v reflect.Value, name string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := v.Type().Elem()
+		if v.IsNil() {
+			if !hasEnvWithPrefix(name) {
+				return nil
+			}
+			v.Set(reflect.New(elem))
+		}
+		return overlayValue(v.Elem(), name)
+
+	case reflect.Struct:
+		if _, ok := os.LookupEnv(name); ok && isTextValue(v) {
+			return setFromEnv(v, name)
+		}
+		for _, f := range configFields(v.Type()) {
+			err := overlayValue(v.FieldByIndex(f.Index), envKey(name, f.Name))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			return overlayMap(v, name)
+		}
+	}
+	return setFromEnv(v, name)
+}
+
+// overlayMap overrides map entries. For a key which is not in the map, the env
+// variable is matched against the fields of the element type, e.g.
+// APP_DATASOURCES_STAGING_DSN creates the entry "staging".
+func overlayMap(v reflect.Value, name string) error {
+	if _, ok := os.LookupEnv(name); ok {
+		return setFromEnv(v, name)
+	}
+	if !hasEnvWithPrefix(name) {
+		return nil
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	// map env names to keys, preferring existing keys
+	keys := map[string]string{}
+	for _, k := range v.MapKeys() {
+		keys[envName(k.String())] = k.String()
+	}
+	for _, env := range os.Environ() {
+		k, _, _ := strings.Cut(env, "=")
+		rest, ok := strings.CutPrefix(k, name+"_")
+		if !ok || rest == "" {
+			continue
+		}
+		mapKey := matchMapKey(v.Type().Elem(), rest)
+		if _, ok := keys[mapKey]; !ok && mapKey != "" {
+			keys[mapKey] = strings.ToLower(mapKey)
+		}
+	}
+
+	for envKeyName, mapKey := range keys {
+		key := reflect.ValueOf(mapKey).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		err := overlayValue(elem, envKey(name, envKeyName))
+		if err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+// matchMapKey extracts the map key from the remainder of an env variable
+// name, by stripping the longest suffix matching a field of the element type.
+func matchMapKey(elem reflect.Type, rest string) string {
+	elem = indirectType(elem)
+	if elem.Kind() != reflect.Struct || reflect.PointerTo(elem).Implements(textUnmarshalerType) {
+		return rest
+	}
+	best := ""
+	for _, suffix := range envSuffixes(elem, "") {
+		if strings.HasSuffix(rest, "_"+suffix) && len(suffix) > len(best) {
+			best = suffix
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return strings.TrimSuffix(rest, "_"+best)
+}
+
+// envSuffixes lists the env name suffixes of all leaf fields of typ
+func envSuffixes(typ reflect.Type, prefix string) []string {
+	suffixes := []string{}
+	for _, f := range configFields(typ) {
+		name := envKey(prefix, f.Name)
+		ft := indirectType(f.Type)
+		if ft.Kind() == reflect.Struct && !reflect.PointerTo(ft).Implements(textUnmarshalerType) {
+			suffixes = append(suffixes, envSuffixes(ft, name)...)
+			continue
+		}
+		suffixes = append(suffixes, name)
+	}
+	return suffixes
+}
+
+func hasEnvWithPrefix(name string) bool {
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, name+"_") || strings.HasPrefix(env, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func isTextValue(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType)
+}
+
+// setFromEnv sets v from the env variable name, if it is set
+func setFromEnv(v reflect.Value, name string) error {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	err := setFromString(v, s)
+	if err != nil {
+		return fmt.Errorf("config: invalid value for %s: %w", name, err)
+	}
+	return nil
+}
+
+// setFromString parses s into v according to the kind of v. Slices are
+// comma-separated, and maps and structs must be json.
+func setFromString(v reflect.Value, s string) error {
+	if isTextValue(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		err := setFromString(elem.Elem(), s)
+		if err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Interface:
+		var i any
+		if json.Unmarshal([]byte(s), &i) != nil {
+			i = s
+		}
+		v.Set(reflect.ValueOf(i))
+	case reflect.Slice:
+		if strings.HasPrefix(strings.TrimSpace(s), "[") {
+			return json.Unmarshal([]byte(s), v.Addr().Interface())
+		}
+		parts := strings.Split(s, ",")
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			err := setFromString(slice.Index(i), strings.TrimSpace(part))
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return json.Unmarshal([]byte(s), v.Addr().Interface())
+	}
+	return nil
+}
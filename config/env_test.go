@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+type testDatasource struct {
+	Driver string `json:"driver"`
+	DSN    string `json:"dsn"`
+}
+
+type testConfig struct {
+	Port        int                       `json:"port"`
+	BindExt     bool                      `json:"bindext"`
+	Timeout     time.Duration             `json:"timeout"`
+	Hosts       []string                  `json:"hosts"`
+	Datasources map[string]testDatasource `json:"datasources"`
+	Bugsnag     *struct {
+		APIKey string `json:"api_key"`
+	} `json:"bugsnag"`
+}
+
+func TestReadConfigEnvOverlay(t *testing.T) {
+	t.Setenv("APP_PORT", "9000")
+	t.Setenv("APP_BINDEXT", "true")
+	t.Setenv("APP_TIMEOUT", "5s")
+	t.Setenv("APP_HOSTS", "a.com, b.com")
+	t.Setenv("APP_DATASOURCES_PRODUCTION_DSN", "postgres://prod")
+	t.Setenv("APP_DATASOURCES_STAGING_DSN", "postgres://staging")
+	t.Setenv("APP_BUGSNAG_API_KEY", "abc")
+
+	m := &Module{Byte: []byte(`{
+		"port": 8000,
+		"datasources": {"production": {"driver": "postgres", "dsn": "postgres://local"}}
+	}`)}
+	cfg := &testConfig{}
+	must.NoError(t, m.ReadConfig(cfg))
+
+	test.Eq(t, 9000, cfg.Port)
+	test.True(t, cfg.BindExt)
+	test.Eq(t, 5*time.Second, cfg.Timeout)
+	test.Eq(t, []string{"a.com", "b.com"}, cfg.Hosts)
+	test.Eq(t, map[string]testDatasource{
+		"production": {Driver: "postgres", DSN: "postgres://prod"},
+		"staging":    {DSN: "postgres://staging"},
+	}, cfg.Datasources)
+	must.NotNil(t, cfg.Bugsnag)
+	test.Eq(t, "abc", cfg.Bugsnag.APIKey)
+}
+
+func TestReadConfigEnvOverlayInvalid(t *testing.T) {
+	t.Setenv("APP_PORT", "not-a-number")
+	m := &Module{Byte: []byte(`{}`)}
+	err := m.ReadConfig(&testConfig{})
+	test.ErrorContains(t, err, "APP_PORT")
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// configField is a struct field which is decoded from the config file
+type configField struct {
+	reflect.StructField
+	Name  string // json key
+	Index []int
+}
+
+// jsonName returns the json key of the struct field f, and false if the
+// field is skipped by encoding/json.
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// configFields returns the fields of the struct type typ which are decoded
+// from json, flattening embedded structs like encoding/json does.
+func configFields(typ reflect.Type) []configField {
+	fields := []configField{}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && f.Tag.Get("json") == "" && ft.Kind() == reflect.Struct {
+			// note: embedded pointers to structs are not flattened
+			if f.Type.Kind() == reflect.Ptr {
+				continue
+			}
+			for _, inner := range configFields(ft) {
+				inner.Index = append([]int{i}, inner.Index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		fields = append(fields, configField{StructField: f, Name: name, Index: []int{i}})
+	}
+	return fields
+}
+
+// indirectType dereferences pointer types
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported config file formats. Regardless of the format, config values
+// are mapped to struct fields using their `json` tags.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// formatFromPath returns the config format for the file extension of path,
+// defaulting to json.
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// decodeFormat decodes b as a config object in the given format
+func decodeFormat(format string, b []byte) (map[string]any, error) {
+	obj := map[string]any{}
+	switch format {
+	case FormatYAML:
+		err := yaml.Unmarshal(b, &obj)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			// empty yaml document
+			obj = map[string]any{}
+		}
+		return normalizeValue(obj).(map[string]any), nil
+	case FormatTOML:
+		err := toml.Unmarshal(b, &obj)
+		return obj, err
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		err := dec.Decode(&obj)
+		return obj, err
+	}
+	return nil, fmt.Errorf("config: unsupported format %q", format)
+}
+
+// encodeFormat encodes the json config data in the given format
+func encodeFormat(format string, data []byte) ([]byte, error) {
+	obj, err := decodeFormat(FormatJSON, data)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(obj, "", "  ")
+	case FormatYAML:
+		return yaml.Marshal(normalizeValue(obj))
+	case FormatTOML:
+		buf := &bytes.Buffer{}
+		err := toml.NewEncoder(buf).Encode(normalizeValue(obj))
+		return buf.Bytes(), err
+	}
+	return nil, fmt.Errorf("config: unsupported format %q", format)
+}
+
+// normalizeValue converts decoded values into types which can be encoded in
+// every format: yaml maps with non-string keys become map[string]any, and
+// numbers become int64 or float64.
+func normalizeValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeValue(e)
+		}
+		return v
+	case map[any]any:
+		obj := map[string]any{}
+		for k, e := range v {
+			obj[fmt.Sprint(k)] = normalizeValue(e)
+		}
+		return obj
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeValue(e)
+		}
+		return v
+	case int:
+		return int64(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
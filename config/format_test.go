@@ -0,0 +1,84 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/octavore/nagax/logger"
+	"github.com/octavore/nagax/util/memlogger"
+)
+
+func TestLoadConfigFormats(t *testing.T) {
+	testCases := []struct {
+		file     string
+		contents string
+	}{{
+		file: "config.yaml",
+		contents: `
+port: 9000
+bindext: true
+hosts: [a.com]
+datasources:
+  production:
+    dsn: postgres://prod
+`,
+	}, {
+		file: "config.toml",
+		contents: `
+port = 9000
+bindext = true
+hosts = ["a.com"]
+
+[datasources.production]
+dsn = "postgres://prod"
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.file, func(t *testing.T) {
+			p := writeFile(t, t.TempDir(), tc.file, tc.contents)
+			m := &Module{Logger: &logger.Module{Logger: &memlogger.MemoryLogger{}}}
+			must.NoError(t, m.LoadConfig(p))
+
+			cfg := &testConfig{}
+			must.NoError(t, m.ReadConfig(cfg))
+			test.Eq(t, &testConfig{
+				Port:        9000,
+				BindExt:     true,
+				Hosts:       []string{"a.com"},
+				Datasources: map[string]testDatasource{"production": {DSN: "postgres://prod"}},
+			}, cfg)
+		})
+	}
+}
+
+func TestEncodeFormat(t *testing.T) {
+	data := []byte(`{"port": 9000, "ratio": 0.5, "hosts": ["a.com"]}`)
+	for _, format := range []string{FormatJSON, FormatYAML, FormatTOML} {
+		t.Run(format, func(t *testing.T) {
+			b, err := encodeFormat(format, data)
+			must.NoError(t, err)
+
+			// round trip
+			obj, err := decodeFormat(format, b)
+			must.NoError(t, err)
+			test.Eq[any](t, map[string]any{
+				"port":  int64(9000),
+				"ratio": 0.5,
+				"hosts": []any{"a.com"},
+			}, normalizeValue(obj))
+		})
+	}
+	_, err := encodeFormat("xml", data)
+	test.Error(t, err)
+}
+
+func TestFormatFromPath(t *testing.T) {
+	test.Eq(t, FormatJSON, formatFromPath("config.json"))
+	test.Eq(t, FormatYAML, formatFromPath(filepath.Join("a", "config.yml")))
+	test.Eq(t, FormatYAML, formatFromPath("config.YAML"))
+	test.Eq(t, FormatTOML, formatFromPath("config.toml"))
+	test.Eq(t, FormatJSON, formatFromPath("config"))
+}
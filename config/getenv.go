@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SecretEnvWords mark env variables as secret if their name contains one of
+// them, in which case their values are masked by config:env.
+var SecretEnvWords = []string{"TOKEN", "SECRET", "PASSWORD", "KEY", "DSN"}
+
+// envVar is an env variable read via one of the Getenv methods
+type envVar struct {
+	Key      string
+	Type     string
+	Default  string
+	Secret   bool
+	Declared []string // file:line of callers
+}
+
+// Getenv reads and caches env variable
+func (m *Module) Getenv(key string) string {
+	m.registerEnv(key, "string", "", false)
+	return m.getenv(key)
+}
+
+// GetenvSecret is like Getenv, but the value is masked by config:env
+func (m *Module) GetenvSecret(key string) string {
+	m.registerEnv(key, "string", "", true)
+	return m.getenv(key)
+}
+
+// GetenvInt reads an int env variable, returning def if it is not set
+func (m *Module) GetenvInt(key string, def int) (int, error) {
+	m.registerEnv(key, "int", strconv.Itoa(def), false)
+	s := m.getenv(key)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def, fmt.Errorf("config: invalid value for %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// GetenvBool reads a bool env variable, returning def if it is not set
+func (m *Module) GetenvBool(key string, def bool) (bool, error) {
+	m.registerEnv(key, "bool", strconv.FormatBool(def), false)
+	s := m.getenv(key)
+	if s == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return def, fmt.Errorf("config: invalid value for %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// GetenvDuration reads an env variable such as "30s", returning def if it is not set
+func (m *Module) GetenvDuration(key string, def time.Duration) (time.Duration, error) {
+	m.registerEnv(key, "duration", def.String(), false)
+	s := m.getenv(key)
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return def, fmt.Errorf("config: invalid value for %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// GetenvURL reads an absolute url env variable, parsing def if it is not set.
+// It returns nil if neither is set, for optional urls.
+func (m *Module) GetenvURL(key string, def string) (*url.URL, error) {
+	m.registerEnv(key, "url", def, false)
+	s := m.getenv(key)
+	if s == "" {
+		s = def
+	}
+	if s == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(s)
+	if err == nil && (u.Scheme == "" || u.Host == "") {
+		err = fmt.Errorf("%q is not an absolute url", s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid value for %s: %w", key, err)
+	}
+	return u, nil
+}
+
+// getenv returns the cached value of the env variable
+func (m *Module) getenv(key string) string {
+	m.envMu.Lock()
+	defer m.envMu.Unlock()
+	if m.Env == nil {
+		m.Env = map[string]string{}
+	}
+	if _, ok := m.Env[key]; !ok {
+		m.Env[key] = os.Getenv(key)
+	}
+	return m.Env[key]
+}
+
+// registerEnv records the env variable and the location of the caller of
+// the public Getenv method
+func (m *Module) registerEnv(key, typ, def string, secret bool) {
+	declared := "<unknown>"
+	if _, file, line, ok := runtime.Caller(2); ok {
+		declared = fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(file)), filepath.Base(file), line)
+	}
+
+	m.envMu.Lock()
+	defer m.envMu.Unlock()
+	if m.envVars == nil {
+		m.envVars = map[string]*envVar{}
+	}
+	v, ok := m.envVars[key]
+	if !ok {
+		v = &envVar{Key: key, Type: typ, Default: def, Secret: isSecretEnv(key)}
+		m.envVars[key] = v
+	}
+	v.Secret = v.Secret || secret
+	if !slices.Contains(v.Declared, declared) {
+		v.Declared = append(v.Declared, declared)
+	}
+}
+
+func isSecretEnv(key string) bool {
+	key = strings.ToUpper(key)
+	for _, word := range SecretEnvWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// printEnv prints all registered env variables with their current values
+func (m *Module) printEnv() {
+	m.envMu.Lock()
+	vars := make([]*envVar, 0, len(m.envVars))
+	for _, v := range m.envVars {
+		vars = append(vars, v)
+	}
+	m.envMu.Unlock()
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Key < vars[j].Key })
+
+	for _, v := range vars {
+		value, ok := os.LookupEnv(v.Key)
+		switch {
+		case !ok && v.Default != "":
+			value = fmt.Sprintf("(unset, default %s)", v.Default)
+		case !ok:
+			value = "(unset)"
+		case v.Secret && value != "":
+			value = secretMask
+		}
+		fmt.Printf("%-24s %-8s %-32s %s\n", v.Key, v.Type, value, strings.Join(v.Declared, ", "))
+	}
+}
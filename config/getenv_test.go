@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestGetenv(t *testing.T) {
+	t.Setenv("TEST_PORT", "9000")
+	t.Setenv("TEST_DEBUG", "yes")
+	t.Setenv("TEST_TIMEOUT", "5s")
+	t.Setenv("TEST_API_TOKEN", "secret")
+
+	m := &Module{}
+	test.Eq(t, "secret", m.Getenv("TEST_API_TOKEN"))
+
+	port, err := m.GetenvInt("TEST_PORT", 8000)
+	test.NoError(t, err)
+	test.Eq(t, 9000, port)
+
+	port, err = m.GetenvInt("TEST_MISSING_PORT", 8000)
+	test.NoError(t, err)
+	test.Eq(t, 8000, port)
+
+	debug, err := m.GetenvBool("TEST_DEBUG", false)
+	test.EqError(t, err, `config: invalid value for TEST_DEBUG: strconv.ParseBool: parsing "yes": invalid syntax`)
+	test.False(t, debug)
+
+	timeout, err := m.GetenvDuration("TEST_TIMEOUT", time.Second)
+	test.NoError(t, err)
+	test.Eq(t, 5*time.Second, timeout)
+
+	u, err := m.GetenvURL("TEST_MISSING_URL", "https://example.com/api")
+	must.NoError(t, err)
+	test.Eq(t, "example.com", u.Host)
+
+	_, err = m.GetenvURL("TEST_PORT", "")
+	test.EqError(t, err, `config: invalid value for TEST_PORT: "9000" is not an absolute url`)
+
+	must.MapLen(t, 6, m.envVars)
+	test.True(t, m.envVars["TEST_API_TOKEN"].Secret)
+	test.False(t, m.envVars["TEST_PORT"].Secret)
+	test.Eq(t, "8000", m.envVars["TEST_PORT"].Default)
+	test.Eq(t, []string{"config/getenv_test.go:20", "config/getenv_test.go:40"}, m.envVars["TEST_PORT"].Declared)
+}
+
+func TestGetenvURLOptional(t *testing.T) {
+	m := &Module{}
+	u, err := m.GetenvURL("TEST_MISSING_URL", "")
+	test.NoError(t, err)
+	test.Nil(t, u)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/octavore/naga/service"
+)
+
+// layerPaths returns the config files which are merged on top of the base
+// config file at path, in order: config.<env>.json and config.local.json.
+// config.local.json is meant to be git-ignored, and is skipped in tests.
+func layerPaths(path string, env string) []string {
+	if env == "" {
+		return nil
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	paths := []string{base + "." + env + ext}
+	if env != service.EnvTest.String() {
+		paths = append(paths, base+".local"+ext)
+	}
+	return paths
+}
+
+// readLayer reads and decodes the config file at path, using the decoder for
+// its extension. Returns nil if the file does not exist.
+func readLayer(path string) (map[string]any, []byte, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	layer, err := decodeFormat(formatFromPath(path), b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config: error parsing %s: %w", path, err)
+	}
+	return layer, b, nil
+}
+
+// mergeLayer deep-merges src into dst. Objects are merged key by key,
+// everything else (including arrays) is replaced.
+func mergeLayer(dst, src map[string]any) {
+	for k, v := range src {
+		srcObj, srcOK := v.(map[string]any)
+		dstObj, dstOK := dst[k].(map[string]any)
+		if srcOK && dstOK {
+			mergeLayer(dstObj, srcObj)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// addSources records path as a source of the top-level keys in layer
+func (m *Module) addSources(layer map[string]any, path string) {
+	for k := range layer {
+		m.sources[k] = append(m.sources[k], path)
+	}
+}
+
+// printSources prints the files each top-level key was read from
+func (m *Module) printSources() {
+	keys := make([]string, 0, len(m.sources))
+	for k := range m.sources {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", k, strings.Join(m.sources[k], ", "))
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/octavore/nagax/logger"
+	"github.com/octavore/nagax/util/memlogger"
+)
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	must.NoError(t, os.WriteFile(p, []byte(contents), 0600))
+	return p
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "config.json", `{
+		"port": 8000,
+		"datasources": {"development": {"driver": "postgres", "dsn": "postgres://base"}},
+		"hosts": ["a.com"]
+	}`)
+	writeFile(t, dir, "config.development.json", `{
+		"datasources": {"development": {"dsn": "postgres://dev"}},
+		"hosts": ["b.com"]
+	}`)
+	writeFile(t, dir, "config.local.json", `{"port": 8001}`)
+	writeFile(t, dir, "config.production.json", `{"port": 80}`)
+
+	m := &Module{Logger: &logger.Module{Logger: &memlogger.MemoryLogger{}}, env: "development"}
+	must.NoError(t, m.LoadConfig(base))
+
+	cfg := &testConfig{}
+	must.NoError(t, m.ReadConfig(cfg))
+	test.Eq(t, 8001, cfg.Port)
+	test.Eq(t, []string{"b.com"}, cfg.Hosts)
+	test.Eq(t, map[string]testDatasource{
+		"development": {Driver: "postgres", DSN: "postgres://dev"},
+	}, cfg.Datasources)
+
+	test.Eq(t, map[string][]string{
+		"port":        {base, filepath.Join(dir, "config.local.json")},
+		"datasources": {base, filepath.Join(dir, "config.development.json")},
+		"hosts":       {base, filepath.Join(dir, "config.development.json")},
+	}, m.sources)
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	m := &Module{Logger: &logger.Module{Logger: &memlogger.MemoryLogger{}}, env: "development"}
+	must.NoError(t, m.LoadConfig(filepath.Join(t.TempDir(), "config.json")))
+	test.Eq(t, `{}`, string(m.Byte))
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"slices"
+
+	"github.com/octavore/nagax/logger"
+)
+
+// configureLogger reads the "logging" config on behalf of the logger module,
+// which cannot depend on this module, and applies it.
+func (m *Module) configureLogger() error {
+	m.loggerConfig = logger.Config{}
+	var err error
+	if slices.Contains(m.targets, any(&m.loggerConfig)) {
+		err = m.decode(&m.loggerConfig)
+		m.setCurrent(&m.loggerConfig)
+	} else {
+		err = m.ReadConfig(&m.loggerConfig)
+	}
+	if err != nil {
+		return err
+	}
+	return m.Logger.SetConfig(m.loggerConfig)
+}
+
+// reloadLogger applies the logging config after a reload
+func (m *Module) reloadLogger(_, new any) {
+	err := m.Logger.SetConfig(*new.(*logger.Config))
+	if err != nil {
+		m.Logger.Errorf("config: error reloading logging config: %v", err)
+	}
+}
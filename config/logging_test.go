@@ -0,0 +1,38 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/octavore/naga/service"
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/octavore/nagax/logger"
+)
+
+func TestConfigureLogger(t *testing.T) {
+	m := &Module{}
+	svc := service.New(m)
+	m.Configure(WithJSONPatch(`{"logging": {
+		"level": "warn",
+		"levels": {"migrate": "debug"},
+		"error_limit": {"window": "5m"}
+	}}`))
+	_, stop := svc.StartForTest()
+	defer stop()
+
+	test.False(t, m.Logger.Enabled("", slog.LevelInfo))
+	test.True(t, m.Logger.Enabled("", slog.LevelWarn))
+	test.True(t, m.Logger.Enabled("migrate", slog.LevelDebug))
+	test.Eq(t, 5*time.Minute, m.loggerConfig.Logging.ErrorLimit.Window)
+}
+
+func TestLoggerConfigFile(t *testing.T) {
+	m := &Module{Byte: []byte(`{"logging": {"file": {"path": "app.log", "max_age": "12h"}}}`)}
+	cfg := &logger.Config{}
+	must.NoError(t, m.ReadConfig(cfg))
+	test.Eq(t, 12*time.Hour, cfg.Logging.File.MaxAge)
+	test.Eq(t, 7, cfg.Logging.File.MaxBackups)
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/octavore/naga/service"
 
@@ -21,36 +23,70 @@ type Module struct {
 	Logger *logger.Module
 
 	Byte       []byte
-	Env        map[string]string
+	Env        map[string]string // cached env variables, see Getenv
 	ConfigPath string
 
 	// TestConfigPath can be to load a specific config file in tests.
 	// Alternatively, you can set Byte directly to the desired config
-	// file contents.
+	// file contents, or patch it with WithOverride and WithJSONPatch.
 	TestConfigPath string
+	testPatches    [][]byte
 
 	configDefs []reflect.Type
+	targets    []any               // pointers passed to ReadConfig
+	configAbs  string              // absolute path of ConfigPath
+	sources    map[string][]string // top-level key => files
+	env        string              // selects the config.<env>.json layer
+	hosted     bool
+
+	loggerConfig   logger.Config // see configureLogger
+	validationErrs []*ValidationError
+	validateOnly   bool // set when running config:validate
+
+	envMu   sync.Mutex
+	envVars map[string]*envVar
+
+	reloadMu          sync.Mutex                   // protects current and reloadSubscribers
+	current           map[any]any                  // ReadConfig target => copy of its current config
+	reloadSubscribers map[any][]func(old, new any) // ReadConfig target => subscribers
+	stopWatch         chan struct{}
 
 	DisableChdir bool
+
+	// Strict reports keys in the config which are not read by any module,
+	// once all modules are set up. Fatal in hosted environments.
+	Strict bool
+
+	// Watch enables reloading the config when the config files change
+	// or on SIGHUP. See OnReload.
+	Watch         bool
+	WatchInterval time.Duration
 }
 
 // Init implements the module interface method
 func (m *Module) Init(c *service.Config) {
 	m.registerCommands(c)
+	m.OnReload(&m.loggerConfig, m.reloadLogger)
 
 	c.Setup = func() error {
 		m.configDefs = []reflect.Type{}
+		m.targets = []any{}
+		m.validationErrs = nil
+		m.env = c.Env().String()
+		m.hosted = c.Env().IsHosted()
+		m.validateOnly = isCommand("config:validate")
+		configEnv := m.Getenv(ConfigEnv)
 		switch {
 		case m.ConfigPath != "":
 		// do nothing
-		case os.Getenv(ConfigEnv) != "":
-			m.ConfigPath = os.Getenv(ConfigEnv)
+		case configEnv != "":
+			m.ConfigPath = configEnv
 		default:
 			m.ConfigPath = "config.json"
 		}
 
-		configAbs, _ := filepath.Abs(m.ConfigPath)
-		m.Logger.Infof("config: %s", configAbs)
+		m.configAbs, _ = filepath.Abs(m.ConfigPath)
+		m.Logger.Infof("config: %s", m.configAbs)
 
 		// we only return an error if production, which
 		// allows this to pass for env=test
@@ -58,58 +94,139 @@ func (m *Module) Init(c *service.Config) {
 		if err != nil {
 			return errIfProduction(c, err)
 		}
+		err = m.configureLogger()
+		if err != nil {
+			return err
+		}
 
 		if !m.DisableChdir {
 			return m.chdirConfigPath()
 		}
 		return nil
 	}
+	c.Start = func() {
+		if m.Strict {
+			err := m.checkStrict()
+			if err != nil && m.hosted {
+				c.Fatal(err)
+			} else if err != nil {
+				m.Logger.Warning(err)
+			}
+		}
+		if !m.Watch {
+			return
+		}
+		interval := m.WatchInterval
+		if interval == 0 {
+			interval = DefaultWatchInterval
+		}
+		m.stopWatch = make(chan struct{})
+		go m.watch(interval, m.stopWatch)
+	}
+
+	c.Stop = func() {
+		if m.stopWatch != nil {
+			close(m.stopWatch)
+			m.stopWatch = nil
+		}
+	}
+
 	c.SetupTest = func() {
 		// this is a hack to have a safe default value
 		// for m.Byte if we haven't loaded any config
 		if len(m.Byte) == 0 {
 			m.Byte = []byte(`{}`)
 		}
-		if m.TestConfigPath == "" {
-			return
+		m.env = c.Env().String()
+		if m.TestConfigPath != "" {
+			err := m.LoadConfig(m.TestConfigPath)
+			if err != nil {
+				c.Fatal(err)
+			}
 		}
-		err := m.LoadConfig(m.TestConfigPath)
+		err := m.applyTestPatches()
+		if err != nil {
+			c.Fatal(err)
+		}
+		err = m.configureLogger()
 		if err != nil {
 			c.Fatal(err)
 		}
 	}
 }
 
-// LoadConfig loads the config json file from the given path
+// LoadConfig loads the config file from the given path, deep-merged
+// with config.<env>.json and config.local.json from the same directory if
+// they exist (see layerPaths). YAML and TOML files are supported based on
+// the file extension, and are converted to json in m.Byte.
 func (m *Module) LoadConfig(path string) error {
-	_, err := os.Stat(path)
+	base, b, err := readLayer(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			m.Byte = []byte(`{}`)
-			return nil
-		}
 		return err
 	}
-	m.Byte, err = os.ReadFile(path)
-	if err != nil {
-		return err
+	if base == nil {
+		base, b = map[string]any{}, []byte(`{}`)
 	}
-	return nil
+	m.sources = map[string][]string{}
+	m.addSources(base, path)
+
+	merged := false
+	for _, p := range layerPaths(path, m.env) {
+		layer, _, err := readLayer(p)
+		if err != nil {
+			return err
+		}
+		if layer == nil {
+			continue
+		}
+		m.Logger.Infof("config: merging %s", p)
+		mergeLayer(base, layer)
+		m.addSources(layer, p)
+		merged = true
+	}
+
+	if !merged && formatFromPath(path) == FormatJSON {
+		m.Byte = b
+		return nil
+	}
+	m.Byte, err = json.MarshalIndent(base, "", "  ")
+	return err
 }
 
 // ReadConfig json-decodes the config file bytes into i, which should be a pointer
-// to a struct.
+// to a struct, after setting fields to the value of their `default` struct tag
+// (supported for scalars, durations such as "12h", and comma-separated slices).
+// Afterwards, fields are overridden by env variables prefixed with
+// EnvPrefix, e.g. APP_DATASOURCES_PRODUCTION_DSN for {"datasources": {"production": {"dsn": ...}}},
+// secret references such as "env:SLACK_TOKEN" are resolved (see SecretResolvers),
+// and fields are checked against their `validate` struct tags (see Validate). i is kept
+// so that fields tagged with `reload:"true"` can be updated by Reload.
 func (m *Module) ReadConfig(i any) error {
 	m.configDefs = append(m.configDefs, reflect.TypeOf(i))
-	return json.Unmarshal(m.Byte, i)
+	m.targets = append(m.targets, i)
+	err := m.decode(i)
+	if err != nil {
+		return err
+	}
+	m.setCurrent(i)
+	return m.validate(i)
 }
 
-// Getenv reads and caches env variable
-func (m *Module) Getenv(key string) string {
-	if _, ok := m.Env[key]; !ok {
-		m.Env[key] = os.Getenv(key)
+// validate i, returning an error only in hosted environments. Elsewhere
+// violations are logged as a warning.
+func (m *Module) validate(i any) error {
+	err := Validate(i)
+	if err == nil {
+		return nil
+	}
+	m.validationErrs = append(m.validationErrs, err.(*ValidationError))
+	if m.hosted && !m.validateOnly {
+		return err
 	}
-	return m.Env[key]
+	if m.Logger != nil && !m.validateOnly {
+		m.Logger.Warning(err)
+	}
+	return nil
 }
 
 func errIfProduction(c *service.Config, err error) error {
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type option func(m *Module)
+
+// WithOverride sets the value at the dot-separated json path, e.g. "port" or
+// "datasources.test.dsn", in the loaded config. Overrides are applied in tests
+// only, after TestConfigPath is loaded and before dependent modules read their
+// config. A nil value removes the key.
+func WithOverride(path string, value any) option {
+	return func(m *Module) {
+		var patch any = value
+		keys := strings.Split(path, ".")
+		for i := len(keys) - 1; i >= 0; i-- {
+			patch = map[string]any{keys[i]: patch}
+		}
+		b, err := json.Marshal(patch)
+		if err != nil {
+			panic(fmt.Sprintf("config: invalid override for %s: %v", path, err))
+		}
+		m.testPatches = append(m.testPatches, b)
+	}
+}
+
+// WithJSONPatch applies a JSON merge patch (RFC 7386) to the loaded config
+// in tests, e.g. `{"port": 0, "bugsnag": null}`. Objects are merged and null
+// removes a key.
+func WithJSONPatch(patch string) option {
+	return func(m *Module) {
+		m.testPatches = append(m.testPatches, []byte(patch))
+	}
+}
+
+// Configure this module with given options
+func (m *Module) Configure(opts ...option) {
+	for _, opt := range opts {
+		opt(m)
+	}
+}
+
+// applyTestPatches applies overrides from WithOverride and WithJSONPatch to m.Byte
+func (m *Module) applyTestPatches() error {
+	if len(m.testPatches) == 0 {
+		return nil
+	}
+	obj, err := decodeFormat(FormatJSON, m.Byte)
+	if err != nil {
+		return err
+	}
+	for _, b := range m.testPatches {
+		patch, err := decodeFormat(FormatJSON, b)
+		if err != nil {
+			return fmt.Errorf("config: invalid patch %s: %w", b, err)
+		}
+		mergePatch(obj, patch)
+	}
+	m.Byte, err = json.MarshalIndent(obj, "", "  ")
+	return err
+}
+
+// mergePatch merges patch into dst following RFC 7386
+func mergePatch(dst, patch map[string]any) {
+	for k, v := range patch {
+		if v == nil {
+			delete(dst, k)
+			continue
+		}
+		patchObj, patchOK := v.(map[string]any)
+		dstObj, dstOK := dst[k].(map[string]any)
+		if patchOK && !dstOK {
+			dstObj = map[string]any{}
+			dst[k] = dstObj
+		}
+		if patchOK {
+			mergePatch(dstObj, patchObj)
+			continue
+		}
+		dst[k] = v
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestApplyTestPatches(t *testing.T) {
+	m := &Module{Byte: []byte(`{
+		"port": 8000,
+		"datasources": {"test": {"driver": "postgres", "dsn": "postgres://test"}},
+		"bugsnag": {"api_key": "abc"}
+	}`)}
+	m.Configure(
+		WithOverride("port", 0),
+		WithOverride("datasources.test.dsn", "postgres://override"),
+		WithJSONPatch(`{"bugsnag": null, "hosts": ["a.com"]}`),
+	)
+	must.NoError(t, m.applyTestPatches())
+	test.EqJSON(t, `{
+		"port": 0,
+		"datasources": {"test": {"driver": "postgres", "dsn": "postgres://override"}},
+		"hosts": ["a.com"]
+	}`, string(m.Byte))
+
+	m.Configure(WithJSONPatch(`not json`))
+	test.Error(t, m.applyTestPatches())
+}
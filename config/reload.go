@@ -0,0 +1,206 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultWatchInterval is how often the config files are checked for changes
+// when Module.Watch is true.
+var DefaultWatchInterval = 2 * time.Second
+
+// OnReload registers fn to be called when the config read into target by
+// ReadConfig changes after a reload. target itself is never modified, since
+// it may be read concurrently. Instead, old and new are pointers to copies of
+// the config before and after the reload, which modules can swap in
+// atomically, e.g. with an atomic.Pointer. They must not be modified.
+//
+// Only fields tagged with `reload:"true"` are updated; changes to other fields
+// are logged and ignored, since they have already been applied during Setup.
+func (m *Module) OnReload(target any, fn func(old, new any)) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if m.reloadSubscribers == nil {
+		m.reloadSubscribers = map[any][]func(old, new any){}
+	}
+	m.reloadSubscribers[target] = append(m.reloadSubscribers[target], fn)
+}
+
+// Reload the config files and re-decode them for every ReadConfig target.
+// Configs are only updated if they decode and validate without errors.
+// Subscribers are called after the reload, so they may call OnReload or Reload.
+func (m *Module) Reload() error {
+	notify, err := m.reload()
+	for _, fn := range notify {
+		fn()
+	}
+	return err
+}
+
+// reload the config, and return the subscriber calls for changed configs
+func (m *Module) reload() ([]func(), error) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	err := m.LoadConfig(m.configAbs)
+	if err != nil {
+		return nil, err
+	}
+	m.Logger.Infof("config: reloaded %s", m.configAbs)
+
+	notify := []func(){}
+	for _, target := range m.targets {
+		old, ok := m.current[target]
+		if !ok {
+			continue
+		}
+		typ := reflect.TypeOf(target).Elem()
+		next := reflect.New(typ)
+		err := m.decode(next.Interface())
+		if err == nil {
+			err = Validate(next.Interface())
+		}
+		if err != nil {
+			m.Logger.Errorf("config: not reloading %s: %v", typ, err)
+			continue
+		}
+
+		updated := reflect.New(typ)
+		updated.Elem().Set(reflect.ValueOf(old).Elem())
+		ignored := []string{}
+		changed := applyReload(updated.Elem(), next.Elem(), "", &ignored)
+		if len(ignored) > 0 {
+			m.Logger.Warningf("config: ignoring changes to non-reloadable fields in %s: %s",
+				typ, strings.Join(ignored, ", "))
+		}
+		if !changed {
+			continue
+		}
+		m.current[target] = updated.Interface()
+		for _, fn := range m.reloadSubscribers[target] {
+			fn, new := fn, updated.Interface()
+			notify = append(notify, func() { fn(old, new) })
+		}
+	}
+	return notify, nil
+}
+
+// setCurrent keeps a copy of the config decoded into target, which reloads
+// are applied to
+func (m *Module) setCurrent(target any) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if m.current == nil {
+		m.current = map[any]any{}
+	}
+	v := reflect.ValueOf(target).Elem()
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	m.current[target] = c.Interface()
+}
+
+// isReloadable returns true if the field is tagged with `reload:"true"`
+func isReloadable(f configField) bool {
+	return f.Tag.Get("reload") == "true"
+}
+
+// applyReload copies reloadable fields from next into cur, and appends the
+// path of changed fields which are not reloadable to ignored. Pointers and maps
+// are replaced rather than modified, so that shallow copies of cur, such as
+// the previous config given to subscribers, are unchanged.
+func applyReload(cur, next reflect.Value, path string, ignored *[]string) bool {
+	changed := false
+	for _, f := range configFields(cur.Type()) {
+		cf, nf := cur.FieldByIndex(f.Index), next.FieldByIndex(f.Index)
+		if !cf.CanSet() || reflect.DeepEqual(cf.Interface(), nf.Interface()) {
+			continue
+		}
+		fieldPath := joinPath(path, f.Name)
+		switch {
+		case isReloadable(f):
+			cf.Set(nf)
+			changed = true
+		case cf.Kind() == reflect.Struct:
+			changed = applyReload(cf, nf, fieldPath, ignored) || changed
+		case cf.Kind() == reflect.Ptr && cf.Type().Elem().Kind() == reflect.Struct && !cf.IsNil() && !nf.IsNil():
+			updated := reflect.New(cf.Type().Elem())
+			updated.Elem().Set(cf.Elem())
+			if applyReload(updated.Elem(), nf.Elem(), fieldPath, ignored) {
+				cf.Set(updated)
+				changed = true
+			}
+		default:
+			*ignored = append(*ignored, fieldPath)
+		}
+	}
+	return changed
+}
+
+// watch polls the config files for changes, and reloads on change or SIGHUP
+func (m *Module) watch(interval time.Duration, stop <-chan struct{}) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastModified := m.modTime()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-sighup:
+			m.Logger.Info("config: got SIGHUP")
+		case <-ticker.C:
+			t := m.modTime()
+			if t.Equal(lastModified) {
+				continue
+			}
+			lastModified = t
+		}
+		err := m.Reload()
+		if err != nil {
+			m.Logger.Errorf("config: error reloading: %v", err)
+		}
+	}
+}
+
+// modTime returns the latest modification time of the config files
+func (m *Module) modTime() time.Time {
+	latest := time.Time{}
+	for _, p := range append([]string{m.configAbs}, layerPaths(m.configAbs, m.env)...) {
+		info, err := os.Stat(p)
+		if err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// decode the config bytes into i with defaults from struct tags, then apply
+// the env overlay and resolve secrets
+func (m *Module) decode(i any) error {
+	err := applyDefaults(i)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return json.Unmarshal(m.Byte, i) // for the json error
+	}
+	err = decodeWithDefaults(v.Elem(), m.Byte, "")
+	if err != nil {
+		return err
+	}
+	err = applyEnvOverlay(i)
+	if err != nil {
+		return err
+	}
+	return resolveSecrets(i)
+}
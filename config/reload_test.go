@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/octavore/nagax/logger"
+	"github.com/octavore/nagax/util/memlogger"
+)
+
+type testReloadConfig struct {
+	Port  int `json:"port"`
+	Slack struct {
+		Channel string `json:"channel" reload:"true"`
+		Token   string `json:"token"`
+	} `json:"slack"`
+	Hosts []string `json:"hosts" reload:"true"`
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	p := writeFile(t, dir, "config.json", `{"port": 8000, "slack": {"channel": "#a", "token": "x"}}`)
+	logs := &memlogger.MemoryLogger{}
+	m := &Module{Logger: &logger.Module{Logger: logs}, configAbs: p}
+	must.NoError(t, m.LoadConfig(p))
+
+	cfg := &testReloadConfig{}
+	must.NoError(t, m.ReadConfig(cfg))
+
+	calls := 0
+	var latest *testReloadConfig
+	m.OnReload(cfg, func(old, new any) {
+		calls++
+		test.Eq(t, "#a", old.(*testReloadConfig).Slack.Channel)
+		latest = new.(*testReloadConfig)
+		// subscribers are called without holding the lock
+		m.OnReload(&testReloadConfig{}, func(old, new any) {})
+	})
+
+	writeFile(t, dir, "config.json", `{"port": 9000, "slack": {"channel": "#b", "token": "y"}, "hosts": ["a.com"]}`)
+	must.NoError(t, m.Reload())
+	test.Eq(t, 1, calls)
+	must.NotNil(t, latest)
+	test.Eq(t, 8000, latest.Port)
+	test.Eq(t, "#b", latest.Slack.Channel)
+	test.Eq(t, "x", latest.Slack.Token)
+	test.Eq(t, []string{"a.com"}, latest.Hosts)
+	test.Eq(t, []string{"config: ignoring changes to non-reloadable fields in config.testReloadConfig: port, slack.token"}, logs.Warnings)
+
+	// the ReadConfig target is not modified
+	test.Eq(t, "#a", cfg.Slack.Channel)
+	test.SliceEmpty(t, cfg.Hosts)
+
+	// unchanged config does not notify subscribers
+	logs.Reset()
+	must.NoError(t, m.Reload())
+	test.Eq(t, 1, calls)
+	must.SliceLen(t, 1, logs.Warnings)
+
+	// invalid config is not applied
+	writeFile(t, dir, "config.json", `{"port": "not-a-port", "slack": {"channel": "#c"}}`)
+	must.NoError(t, m.Reload())
+	test.Eq(t, 1, calls)
+	test.Eq(t, "#b", latest.Slack.Channel)
+	must.SliceLen(t, 1, logs.Errors)
+}
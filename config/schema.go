@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of JSON Schema used to describe config structs
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *float64               `json:"minLength,omitempty"`
+	MaxLength            *float64               `json:"maxLength,omitempty"`
+	MinItems             *float64               `json:"minItems,omitempty"`
+	MaxItems             *float64               `json:"maxItems,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing the config file,
+// generated from every struct passed to ReadConfig. Field descriptions are read
+// from `desc` struct tags, defaults from `default` tags, and constraints from
+// `validate` tags.
+func (m *Module) JSONSchema() ([]byte, error) {
+	root := &jsonSchema{
+		Schema:     schemaDraft,
+		Title:      "config",
+		Type:       "object",
+		Properties: map[string]*jsonSchema{},
+	}
+	for _, typ := range m.configDefs {
+		s := typeSchema(typ, map[reflect.Type]bool{})
+		mergeSchema(root, s)
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// mergeSchema merges the properties of the object schema src into dst
+func mergeSchema(dst, src *jsonSchema) {
+	for k, prop := range src.Properties {
+		existing, ok := dst.Properties[k]
+		if ok && existing.Properties != nil && prop.Properties != nil {
+			mergeSchema(existing, prop)
+			continue
+		}
+		if !ok {
+			dst.Properties[k] = prop
+		}
+	}
+	for _, r := range src.Required {
+		if !slices.Contains(dst.Required, r) {
+			dst.Required = append(dst.Required, r)
+		}
+	}
+}
+
+// typeSchema returns the schema for typ. seen guards against recursive types.
+func typeSchema(typ reflect.Type, seen map[reflect.Type]bool) *jsonSchema {
+	if typ.Kind() == reflect.Ptr {
+		s := typeSchema(typ.Elem(), seen)
+		switch t := s.Type.(type) {
+		case string:
+			s.Type = []string{t, "null"}
+		case []string:
+			s.Type = append(t, "null")
+		}
+		return s
+	}
+	if typ == durationType {
+		return &jsonSchema{Type: []string{"string", "integer"}, Description: `duration such as "10s", or nanoseconds`}
+	}
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return &jsonSchema{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &jsonSchema{Type: "string", Format: "byte"}
+		}
+		return &jsonSchema{Type: "array", Items: typeSchema(typ.Elem(), seen)}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: typeSchema(typ.Elem(), seen)}
+	case reflect.Struct:
+		if seen[typ] {
+			return &jsonSchema{}
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
+		s := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}}
+		for _, f := range configFields(typ) {
+			prop := typeSchema(f.Type, seen)
+			if desc := f.Tag.Get("desc"); desc != "" {
+				prop.Description = desc
+			}
+			if d, ok := f.Tag.Lookup("default"); ok {
+				prop.Default = schemaDefault(f.Type, d)
+			}
+			if applyValidateSchema(prop, f) {
+				s.Required = append(s.Required, f.Name)
+			}
+			s.Properties[f.Name] = prop
+		}
+		return s
+	}
+	// interfaces and other types accept any value
+	return &jsonSchema{}
+}
+
+// schemaDefault converts a `default` struct tag into a json value
+func schemaDefault(typ reflect.Type, d string) any {
+	if indirectType(typ) == durationType {
+		return d // e.g. "10s"
+	}
+	v := reflect.New(typ).Elem()
+	if setFromString(v, d) != nil {
+		return d
+	}
+	return v.Interface()
+}
+
+// applyValidateSchema adds constraints from the `validate` tag of f to s,
+// returning true if the field is required
+func applyValidateSchema(s *jsonSchema, f configField) bool {
+	required := false
+	kind := indirectType(f.Type).Kind()
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			for _, opt := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, enumValue(kind, opt))
+			}
+		case "url":
+			s.Format = "uri"
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue // e.g. durations
+			}
+			lower, upper := &s.Minimum, &s.Maximum
+			switch kind {
+			case reflect.String:
+				lower, upper = &s.MinLength, &s.MaxLength
+			case reflect.Slice, reflect.Array, reflect.Map:
+				lower, upper = &s.MinItems, &s.MaxItems
+			}
+			if name == "min" {
+				*lower = &n
+			} else {
+				*upper = &n
+			}
+		}
+	}
+	return required
+}
+
+func enumValue(kind reflect.Kind, opt string) any {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(opt, 64); err == nil {
+			return n
+		}
+	}
+	return opt
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+type testSchemaConfig struct {
+	Port    int    `json:"port" desc:"port to listen on" default:"8000" validate:"min=1,max=65535"`
+	Env     string `json:"env" validate:"required,oneof=dev prod"`
+	Bugsnag *struct {
+		APIKey string `json:"api_key" validate:"required"`
+	} `json:"bugsnag"`
+	Datasources map[string]testDatasource `json:"datasources"`
+	Hosts       []string                  `json:"hosts" validate:"max=2"`
+	Extra       any                       `json:"extra"`
+}
+
+type testSchemaConfig2 struct {
+	Datasources map[string]testDatasource `json:"datasources"`
+	Webhook     string                    `json:"webhook" validate:"url"`
+	Timeout     *time.Duration            `json:"timeout" default:"10s"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	m := &Module{configDefs: []reflect.Type{
+		reflect.TypeOf(&testSchemaConfig{}),
+		reflect.TypeOf(&testSchemaConfig2{}),
+	}}
+	b, err := m.JSONSchema()
+	must.NoError(t, err)
+	test.EqJSON(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "config",
+		"type": "object",
+		"properties": {
+			"port": {"type": "integer", "description": "port to listen on", "default": 8000, "minimum": 1, "maximum": 65535},
+			"env": {"type": "string", "enum": ["dev", "prod"]},
+			"bugsnag": {
+				"type": ["object", "null"],
+				"properties": {"api_key": {"type": "string"}},
+				"required": ["api_key"]
+			},
+			"datasources": {
+				"type": "object",
+				"additionalProperties": {
+					"type": "object",
+					"properties": {"driver": {"type": "string"}, "dsn": {"type": "string"}}
+				}
+			},
+			"hosts": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"extra": {},
+			"webhook": {"type": "string", "format": "uri"},
+			"timeout": {"type": ["string", "integer", "null"], "description": "duration such as \"10s\", or nanoseconds", "default": "10s"}
+		},
+		"required": ["env"]
+	}`, string(b))
+}
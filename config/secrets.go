@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// SecretResolvers resolve string config values of the form "<scheme>:<ref>",
+// e.g. "env:SLACK_TOKEN", "file:/run/secrets/dsn" or "base64:c2VjcmV0".
+// File references must be absolute paths without a query, so that sqlite
+// DSNs such as "file:app.db?cache=shared" are read as is.
+var SecretResolvers = map[string]func(ref string) (string, error){
+	"env": func(ref string) (string, error) {
+		v, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("env variable %s is not set", ref)
+		}
+		return v, nil
+	},
+	"file": func(ref string) (string, error) {
+		b, err := os.ReadFile(ref)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	},
+	"base64": func(ref string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(ref)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+}
+
+const secretMask = "********"
+
+// secretResolver returns the resolver for s, if s is a secret reference
+func secretResolver(s string) (func(string) (string, error), string, bool) {
+	scheme, ref, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, "", false
+	}
+	if scheme == "file" && !isSecretFile(ref) {
+		return nil, "", false
+	}
+	resolve, ok := SecretResolvers[scheme]
+	return resolve, ref, ok
+}
+
+// isSecretFile returns false for file: values which are sqlite DSNs rather
+// than paths, e.g. "file:app.db?cache=shared" or "file:///data/app.db"
+func isSecretFile(ref string) bool {
+	return strings.HasPrefix(ref, "/") && !strings.HasPrefix(ref, "//") && !strings.Contains(ref, "?")
+}
+
+// resolveSecrets replaces secret references in the string fields of i
+func resolveSecrets(i any) error {
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	return resolveValue(v.Elem(), "")
+}
+
+func resolveValue(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.String:
+		resolve, ref, ok := secretResolver(v.String())
+		if !ok {
+			return nil
+		}
+		s, err := resolve(ref)
+		if err != nil {
+			return fmt.Errorf("config: error resolving secret for %s: %w", path, err)
+		}
+		v.SetString(s)
+
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			// values in an interface are not settable, so resolve a copy
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			err := resolveValue(elem, path)
+			if err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		}
+		return resolveValue(v.Elem(), path)
+
+	case reflect.Struct:
+		for _, f := range configFields(v.Type()) {
+			fv := v.FieldByIndex(f.Index)
+			if !fv.CanSet() || f.Tag.Get("secret") == "false" {
+				continue
+			}
+			err := resolveValue(fv, joinPath(path, f.Name))
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		for _, k := range sortedKeys(v) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			err := resolveValue(elem, joinPath(path, fmt.Sprint(k)))
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(k, elem)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			err := resolveValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// maskSecrets replaces secret references in the json config data with a
+// mask, so that e.g. base64 secrets are not printed.
+func maskSecrets(data []byte) []byte {
+	obj, err := decodeFormat(FormatJSON, data)
+	if err != nil || !maskValue(obj) {
+		return data
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if enc.Encode(obj) != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+// maskValue masks secret references in v, returning true if any were found
+func maskValue(v any) bool {
+	masked := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if s, ok := e.(string); ok {
+				if _, _, ok := secretResolver(s); ok {
+					v[k] = secretMask
+					masked = true
+				}
+				continue
+			}
+			masked = maskValue(e) || masked
+		}
+	case []any:
+		for i, e := range v {
+			if s, ok := e.(string); ok {
+				if _, _, ok := secretResolver(s); ok {
+					v[i] = secretMask
+					masked = true
+				}
+				continue
+			}
+			masked = maskValue(e) || masked
+		}
+	}
+	return masked
+}
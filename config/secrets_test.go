@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+type testSecretConfig struct {
+	APIKey      string                    `json:"api_key"`
+	Token       *string                   `json:"token"`
+	Datasources map[string]testDatasource `json:"datasources"`
+	Hosts       []string                  `json:"hosts"`
+	Raw         string                    `json:"raw" secret:"false"`
+}
+
+func TestReadConfigSecrets(t *testing.T) {
+	t.Setenv("TEST_SLACK_TOKEN", "slack-token")
+	p := writeFile(t, t.TempDir(), "dsn", "postgres://secret\n")
+
+	m := &Module{Byte: []byte(`{
+		"api_key": "base64:c2VjcmV0",
+		"token": "env:TEST_SLACK_TOKEN",
+		"datasources": {"production": {"driver": "postgres", "dsn": "file:` + p + `"}},
+		"hosts": ["a.com", "env:TEST_SLACK_TOKEN"],
+		"raw": "env:TEST_SLACK_TOKEN"
+	}`)}
+	cfg := &testSecretConfig{}
+	must.NoError(t, m.ReadConfig(cfg))
+
+	test.Eq(t, "secret", cfg.APIKey)
+	must.NotNil(t, cfg.Token)
+	test.Eq(t, "slack-token", *cfg.Token)
+	test.Eq(t, "postgres://secret", cfg.Datasources["production"].DSN)
+	test.Eq(t, []string{"a.com", "slack-token"}, cfg.Hosts)
+	test.Eq(t, "env:TEST_SLACK_TOKEN", cfg.Raw)
+}
+
+func TestReadConfigSecretsPassthrough(t *testing.T) {
+	m := &Module{Byte: []byte(`{
+		"api_key": "mailto:admin@example.com",
+		"datasources": {
+			"test": {"driver": "sqlite3", "dsn": "file:app.db?cache=shared"},
+			"ro": {"driver": "sqlite3", "dsn": "file:///data/app.db?mode=ro"}
+		}
+	}`)}
+	cfg := &testSecretConfig{}
+	must.NoError(t, m.ReadConfig(cfg))
+
+	// sqlite DSNs and unknown schemes are not resolved
+	test.Eq(t, "mailto:admin@example.com", cfg.APIKey)
+	test.Eq(t, "file:app.db?cache=shared", cfg.Datasources["test"].DSN)
+	test.Eq(t, "file:///data/app.db?mode=ro", cfg.Datasources["ro"].DSN)
+	test.Eq(t, []byte(`{"dsn": "file:app.db"}`), maskSecrets([]byte(`{"dsn": "file:app.db"}`)))
+}
+
+func TestReadConfigSecretsError(t *testing.T) {
+	m := &Module{Byte: []byte(`{"datasources": {"production": {"dsn": "env:TEST_MISSING_DSN"}}}`)}
+	err := m.ReadConfig(&testSecretConfig{})
+	test.EqError(t, err, "config: error resolving secret for datasources.production.dsn: env variable TEST_MISSING_DSN is not set")
+}
+
+func TestMaskSecrets(t *testing.T) {
+	data := []byte(`{"api_key": "base64:c2VjcmV0", "hosts": ["a.com", "env:TOKEN"], "port": 8000}`)
+	test.EqJSON(t, `{"api_key": "********", "hosts": ["a.com", "********"], "port": 8000}`, string(maskSecrets(data)))
+
+	// unchanged if there are no secrets
+	data = []byte(`{"port": 8000}`)
+	test.Eq(t, data, maskSecrets(data))
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnknownKeysError lists config keys which were not read by any module
+type UnknownKeysError struct {
+	Paths []string
+}
+
+func (e *UnknownKeysError) Error() string {
+	return fmt.Sprintf("config: unknown key(s) not used by any module: %s", strings.Join(e.Paths, ", "))
+}
+
+// UnknownKeys returns the json paths of keys in the config which do not match
+// a field in any of the structs passed to ReadConfig so far.
+func (m *Module) UnknownKeys() ([]string, error) {
+	obj, err := decodeFormat(FormatJSON, m.Byte)
+	if err != nil {
+		return nil, err
+	}
+	return unknownKeys(obj, m.configDefs, ""), nil
+}
+
+// checkStrict returns an error if there are unknown keys in the config
+func (m *Module) checkStrict() error {
+	paths, err := m.UnknownKeys()
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return &UnknownKeysError{Paths: paths}
+}
+
+// unknownKeys walks the decoded json value v, where types are the candidate
+// types which v may be decoded into, and returns the paths of unclaimed keys.
+func unknownKeys(v any, types []reflect.Type, path string) []string {
+	unknown := []string{}
+	switch v := v.(type) {
+	case map[string]any:
+		structs, elems := []reflect.Type{}, []reflect.Type{}
+		for _, t := range types {
+			t = indirectType(t)
+			switch {
+			case t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(textUnmarshalerType):
+				return unknown // accepts any value
+			case t.Kind() == reflect.Struct:
+				structs = append(structs, t)
+			case t.Kind() == reflect.Map:
+				elems = append(elems, t.Elem())
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			next := append([]reflect.Type{}, elems...)
+			for _, s := range structs {
+				if f, ok := lookupField(s, k); ok {
+					next = append(next, f.Type)
+				}
+			}
+			if len(next) == 0 {
+				unknown = append(unknown, joinPath(path, k))
+				continue
+			}
+			unknown = append(unknown, unknownKeys(v[k], next, joinPath(path, k))...)
+		}
+
+	case []any:
+		elems := []reflect.Type{}
+		for _, t := range types {
+			t = indirectType(t)
+			switch t.Kind() {
+			case reflect.Interface:
+				return unknown
+			case reflect.Slice, reflect.Array:
+				elems = append(elems, t.Elem())
+			}
+		}
+		for i, e := range v {
+			unknown = append(unknown, unknownKeys(e, elems, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return unknown
+}
+
+// lookupField finds the field for a json key, matching case-insensitively
+// like encoding/json does.
+func lookupField(typ reflect.Type, key string) (configField, bool) {
+	fields := configFields(typ)
+	for _, f := range fields {
+		if f.Name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, key) {
+			return f, true
+		}
+	}
+	return configField{}, false
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestUnknownKeys(t *testing.T) {
+	m := &Module{Byte: []byte(`{
+		"port": 8000,
+		"bindexternal": true,
+		"Hosts": ["a.com"],
+		"datasources": {"production": {"driver": "postgres", "dns": "typo"}},
+		"bugsnag": {"api_key": "abc", "apikey": "abc"},
+		"slack": {"channel": "#a"}
+	}`)}
+	must.NoError(t, m.ReadConfig(&testConfig{}))
+	must.NoError(t, m.ReadConfig(&struct {
+		Slack map[string]any `json:"slack"`
+	}{}))
+
+	paths, err := m.UnknownKeys()
+	must.NoError(t, err)
+	test.Eq(t, []string{"bindexternal", "bugsnag.apikey", "datasources.production.dns"}, paths)
+	test.EqError(t, m.checkStrict(), "config: unknown key(s) not used by any module: bindexternal, bugsnag.apikey, datasources.production.dns")
+}
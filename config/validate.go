@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation of a `validate` struct tag rule
+type Violation struct {
+	Path    string // json path of the field, e.g. datasources.production.dsn
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// ValidationError collects all violations found in a config struct
+type ValidationError struct {
+	Type       reflect.Type
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{fmt.Sprintf("config: %d invalid value(s) in %s", len(e.Violations), e.Type)}
+	for _, v := range e.Violations {
+		lines = append(lines, "  "+v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks the fields of i against the rules in their `validate`
+// struct tags, and returns a *ValidationError listing every violation.
+// Rules are comma-separated:
+//
+//	required      value must not be the zero value
+//	min=N, max=N  bounds for numbers, durations (e.g. min=1s), or the length
+//	              of strings, slices and maps
+//	oneof=a b c   value must be one of the space-separated options
+//	url           value must be an absolute url
+//	duration      value must be parsable by time.ParseDuration
+//
+// Except for required, rules are not checked for empty values.
+func Validate(i any) error {
+	v := reflect.ValueOf(i)
+	violations := validateValue(v, "", "")
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Type: indirectType(v.Type()), Violations: violations}
+}
+
+func validateValue(v reflect.Value, path, tag string) []Violation {
+	violations := []Violation{}
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "" {
+			continue
+		}
+		msg := checkRule(v, rule)
+		if msg != "" {
+			violations = append(violations, Violation{Path: path, Message: msg})
+		}
+	}
+
+	// recurse into nested config
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			violations = append(violations, validateValue(v.Elem(), path, "")...)
+		}
+	case reflect.Struct:
+		for _, f := range configFields(v.Type()) {
+			fv := v.FieldByIndex(f.Index)
+			violations = append(violations, validateValue(fv, joinPath(path, f.Name), f.Tag.Get("validate"))...)
+		}
+	case reflect.Map:
+		for _, k := range sortedKeys(v) {
+			violations = append(violations, validateValue(v.MapIndex(k), joinPath(path, fmt.Sprint(k)), "")...)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			violations = append(violations, validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), "")...)
+		}
+	}
+	return violations
+}
+
+// checkRule returns a message if v does not satisfy rule
+func checkRule(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	if name == "required" {
+		if v.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+	if v.IsZero() {
+		return ""
+	}
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch name {
+	case "min", "max":
+		n, limit, err := ruleBounds(v, arg)
+		if err != nil {
+			return fmt.Sprintf("invalid rule %q: %v", rule, err)
+		}
+		if name == "min" && n < limit {
+			return fmt.Sprintf("must be at least %s", arg)
+		}
+		if name == "max" && n > limit {
+			return fmt.Sprintf("must be at most %s", arg)
+		}
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, opt := range strings.Fields(arg) {
+			if s == opt {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s], got %q", arg, s)
+	case "url":
+		u, err := url.Parse(v.String())
+		if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+			return fmt.Sprintf("must be an absolute url, got %q", v.String())
+		}
+	case "duration":
+		if _, err := time.ParseDuration(v.String()); err != nil {
+			return fmt.Sprintf("must be a duration, got %q", v.String())
+		}
+	default:
+		return fmt.Sprintf("unknown rule %q", rule)
+	}
+	return ""
+}
+
+// ruleBounds returns the value to compare for min and max rules, along
+// with the parsed limit
+func ruleBounds(v reflect.Value, arg string) (float64, float64, error) {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(arg)
+		return float64(v.Int()), float64(d), err
+	}
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), limit, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), limit, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), limit, nil
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), limit, nil
+	}
+	return 0, 0, fmt.Errorf("not supported for %s", v.Type())
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// sortedKeys returns the keys of the map v in a stable order
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	return keys
+}
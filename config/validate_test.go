@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+type testValidatedConfig struct {
+	Port        int                 `json:"port" validate:"min=1,max=65535"`
+	Env         string              `json:"env" validate:"oneof=dev prod"`
+	Endpoint    string              `json:"endpoint" validate:"url"`
+	Interval    string              `json:"interval" validate:"duration"`
+	Timeout     time.Duration       `json:"timeout" validate:"max=1m"`
+	Hosts       []string            `json:"hosts" validate:"max=2"`
+	Datasources map[string]struct { // nested rules are checked too
+		Driver string `json:"driver" validate:"required,oneof=postgres mysql"`
+	} `json:"datasources"`
+}
+
+func TestValidate(t *testing.T) {
+	m := &Module{Byte: []byte(`{
+		"port": 70000,
+		"env": "staging",
+		"endpoint": "not-a-url",
+		"interval": "5 minutes",
+		"timeout": 120000000000,
+		"hosts": ["a", "b", "c"],
+		"datasources": {"production": {}, "test": {"driver": "sqlite"}}
+	}`)}
+	must.NoError(t, m.ReadConfig(&testValidatedConfig{}))
+	must.SliceLen(t, 1, m.validationErrs)
+	test.Eq(t, []Violation{
+		{Path: "port", Message: "must be at most 65535"},
+		{Path: "env", Message: `must be one of [dev prod], got "staging"`},
+		{Path: "endpoint", Message: `must be an absolute url, got "not-a-url"`},
+		{Path: "interval", Message: `must be a duration, got "5 minutes"`},
+		{Path: "timeout", Message: "must be at most 1m"},
+		{Path: "hosts", Message: "must be at most 2"},
+		{Path: "datasources.production.driver", Message: "is required"},
+		{Path: "datasources.test.driver", Message: `must be one of [postgres mysql], got "sqlite"`},
+	}, m.validationErrs[0].Violations)
+
+	// hosted environments return the error
+	m.hosted = true
+	err := m.ReadConfig(&testValidatedConfig{})
+	test.ErrorContains(t, err, "config: 8 invalid value(s) in config.testValidatedConfig")
+}
+
+func TestValidateOK(t *testing.T) {
+	m := &Module{Byte: []byte(`{
+		"port": 8000,
+		"env": "dev",
+		"endpoint": "https://example.com/hook",
+		"interval": "5m",
+		"datasources": {"production": {"driver": "postgres"}}
+	}`), hosted: true}
+	must.NoError(t, m.ReadConfig(&testValidatedConfig{}))
+	test.SliceEmpty(t, m.validationErrs)
+}
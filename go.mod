@@ -3,6 +3,7 @@ module github.com/octavore/nagax
 go 1.23
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/NYTimes/gziphandler v1.1.1
 	github.com/bugsnag/bugsnag-go/v2 v2.5.1
 	github.com/fatih/color v1.18.0
@@ -18,6 +19,7 @@ require (
 	golang.org/x/oauth2 v0.25.0
 	google.golang.org/protobuf v1.36.2
 	gopkg.in/cenkalti/backoff.v2 v2.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,96 @@
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"time"
+)
+
+// localhostCertValidity is how long generated localhost certificates are valid
+const localhostCertValidity = 365 * 24 * time.Hour
+
+// LoadLocalhostCert returns the paths of a self-signed certificate and key for
+// localhost, 127.0.0.1 and ::1, for serving https in development. They are
+// generated if either file is missing or the certificate has expired.
+func (k *KeyStore) LoadLocalhostCert(certFileName, keyFileName string) (string, string, error) {
+	certFile := path.Join(k.Dir, certFileName)
+	keyFile := path.Join(k.Dir, keyFileName)
+	if validCert(certFile, keyFile) {
+		return certFile, keyFile, nil
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"nagax development"}, CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(localhostCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true, // not a CA, so trusting it cannot be used to sign other certificates
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return "", "", err
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = writePEM(keyFile, "EC PRIVATE KEY", keyBytes, 0600)
+	if err != nil {
+		return "", "", err
+	}
+	err = writePEM(certFile, "CERTIFICATE", der, 0644)
+	if err != nil {
+		return "", "", err
+	}
+	return certFile, keyFile, nil
+}
+
+// validCert returns true if the certificate and key can be loaded and the
+// certificate has not expired
+func validCert(certFile, keyFile string) bool {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return false
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false
+	}
+	return time.Now().Before(leaf.NotAfter)
+}
+
+func writePEM(fileName, blockType string, b []byte, perm os.FileMode) error {
+	err := os.MkdirAll(path.Dir(fileName), 0700)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_RDWR|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return pem.Encode(f, &pem.Block{Type: blockType, Bytes: b})
+}
@@ -0,0 +1,25 @@
+package logger
+
+import "context"
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx with the key-value pairs added to its log
+// fields. The *Ctx and *KV methods of the loggers in this package include the
+// fields of their context, e.g. a request ID set by a middleware.
+func WithFields(ctx context.Context, kv ...any) context.Context {
+	if len(kv) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldsKey{}, append(Fields(ctx), kv...))
+}
+
+// Fields returns the key-value pairs added to ctx with WithFields
+func Fields(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+	return fields[:len(fields):len(fields)] // appending must copy
+
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"context"
+	"log"
+	"log/slog"
+)
+
+// textHandler is the default slog.Handler, which prints lines such as
+// `[INFO] name: msg key=value` using the standard log package, where name is
+// the "logger" attribute set by named loggers.
+type textHandler struct {
+	logger *log.Logger // defaults to log.Default()
+	attrs  []any       // key-value pairs from WithAttrs
+	group  string      // key prefix from WithGroup
+}
+
+func (h *textHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (h *textHandler) Handle(ctx context.Context, r slog.Record) error {
+	prefix := ""
+	kv := append([]any{}, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "logger" && h.group == "" {
+			prefix = a.Value.String() + ": "
+			return true
+		}
+		if err, ok := a.Value.Any().(error); ok && a.Key == "error" && err.Error() == r.Message {
+			return true // already the message, see DefaultLogger.logArgs
+		}
+		kv = appendAttr(kv, h.group, a)
+		return true
+	})
+	l := h.logger
+	if l == nil {
+		l = log.Default()
+	}
+	return l.Output(2, "["+r.Level.String()+"] "+FormatKV(prefix+r.Message, kv...))
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]any{}, h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.group, a)
+	}
+	return &h2
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// appendAttr appends a as key-value pairs to kv, flattening groups into
+// dot-separated keys.
+func appendAttr(kv []any, prefix string, a slog.Attr) []any {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			kv = appendAttr(kv, prefix, ga)
+		}
+		return kv
+	}
+	if a.Equal(slog.Attr{}) {
+		return kv
+	}
+	return append(kv, prefix+a.Key, v.Any())
+}
+
+// pipelineHandler is a slog.Handler which logs records through the Logger of
+// the module, so wrappers such as bugsnag see them.
+type pipelineHandler struct {
+	module *Module
+	attrs  []any
+	group  string
+}
+
+func (h *pipelineHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (h *pipelineHandler) Handle(ctx context.Context, r slog.Record) error {
+	kv := append([]any{}, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		kv = appendAttr(kv, h.group, a)
+		return true
+	})
+	switch {
+	case r.Level >= slog.LevelError:
+		h.module.Logger.ErrorKV(ctx, r.Message, kv...)
+	case r.Level >= slog.LevelWarn:
+		h.module.Logger.WarningKV(ctx, r.Message, kv...)
+	case r.Level >= slog.LevelInfo:
+		h.module.Logger.InfoKV(ctx, r.Message, kv...)
+	default:
+		h.module.Logger.DebugKV(ctx, r.Message, kv...)
+	}
+	return nil
+}
+
+func (h *pipelineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]any{}, h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.group, a)
+	}
+	return &h2
+}
+
+func (h *pipelineHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	return &h2
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatKV formats msg followed by key-value pairs in logfmt style, e.g.
+// `msg key=value other="with space"`. A key without a value is given the
+// value "!MISSING".
+func FormatKV(msg string, kv ...any) string {
+	b := &strings.Builder{}
+	b.WriteString(msg)
+	for i := 0; i < len(kv); i += 2 {
+		var v any = "!MISSING"
+		if i+1 < len(kv) {
+			v = kv[i+1]
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(fmt.Sprint(kv[i]))
+		b.WriteByte('=')
+		b.WriteString(formatValue(v))
+	}
+	return b.String()
+}
+
+// formatValue formats v, quoting it if necessary
+func formatValue(v any) string {
+	if v == nil {
+		return "<nil>"
+	}
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
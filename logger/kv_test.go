@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shoenig/test"
+)
+
+func TestFormatKV(t *testing.T) {
+	testCases := []struct {
+		msg      string
+		kv       []any
+		expected string
+	}{
+		{"hello", nil, "hello"},
+		{"hello", []any{"a", 1, "b", true}, "hello a=1 b=true"},
+		{"hello", []any{"a", "", "b", "with space", "c", `q"uote`}, `hello a="" b="with space" c="q\"uote"`},
+		{"hello", []any{"err", fmt.Errorf("bad"), "nil", nil}, "hello err=bad nil=<nil>"},
+		{"hello", []any{"a"}, "hello a=!MISSING"},
+		{"", []any{"a", 1}, "a=1"},
+	}
+	for _, tc := range testCases {
+		test.Eq(t, tc.expected, FormatKV(tc.msg, tc.kv...))
+	}
+}
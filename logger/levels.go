@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config for the logger module. It is read by the config module, since the
+// config module itself depends on the logger.
+type Config struct {
+	Logging struct {
+		Level  string            `json:"level" default:"info" reload:"true" validate:"oneof=debug info warn warning error" desc:"minimum log level: debug, info, warn or error"`
+		Levels map[string]string `json:"levels" reload:"true" desc:"minimum log level by logger name"`
+		Format string            `json:"format" default:"text" validate:"oneof=text json" desc:"output format: text, or json for one object per line"`
+
+		// ErrorLimit limits repeated errors with the same type and stack
+		// location, which are then summarized at the end of the window.
+		ErrorLimit struct {
+			Burst  int           `json:"burst" default:"10" validate:"min=0" desc:"errors logged per window for each type and location, 0 to log all"`
+			Window time.Duration `json:"window" default:"1m" desc:"window for error_limit.burst"`
+		} `json:"error_limit"`
+
+		// File is an additional output, which is rotated by size and age
+		File struct {
+			Path       string        `json:"path" desc:"also log to this file"`
+			MaxSize    int           `json:"max_size" default:"100" validate:"min=0" desc:"rotate the file at this size in MB, 0 for no limit"`
+			MaxAge     time.Duration `json:"max_age" default:"24h" validate:"min=0" desc:"rotate the file at this age, 0 for no limit"`
+			MaxBackups int           `json:"max_backups" default:"7" validate:"min=0" desc:"rotated files to keep, 0 keeps all"`
+			Compress   bool          `json:"compress" default:"true" desc:"gzip rotated files"`
+		} `json:"file"`
+	} `json:"logging"`
+}
+
+// levels holds the minimum log level, globally and by logger name
+type levels struct {
+	mu     sync.RWMutex
+	level  slog.Level
+	byName map[string]slog.Level
+}
+
+// enabled returns true if level is at least the minimum level for the logger
+// name, or the global minimum level if name has none
+func (l *levels) enabled(name string, level slog.Level) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if minLevel, ok := l.byName[name]; ok && name != "" {
+		return level >= minLevel
+	}
+	return level >= l.level
+}
+
+// parseLevel parses debug, info, warn (or warning) and error
+func parseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if strings.EqualFold(s, "warning") {
+		s = "warn"
+	}
+	err := level.UnmarshalText([]byte(s))
+	if err != nil {
+		return level, fmt.Errorf("logger: invalid level %q", s)
+	}
+	return level, nil
+}
+
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// SetConfig sets the output format, file output, error limit and the minimum
+// log levels from c, replacing levels set with SetLevel.
+func (m *Module) SetConfig(c Config) error {
+	if c.Logging.Format != "" {
+		err := m.SetFormat(c.Logging.Format)
+		if err != nil {
+			return err
+		}
+	}
+	if c.Logging.File.Path != "" && m.file == nil {
+		m.file = &RotatingFile{
+			Path:       c.Logging.File.Path,
+			MaxSize:    int64(c.Logging.File.MaxSize) << 20,
+			MaxAge:     c.Logging.File.MaxAge,
+			MaxBackups: c.Logging.File.MaxBackups,
+			Compress:   c.Logging.File.Compress,
+		}
+		m.defaultLogger.SetFileOutput(m.file)
+	}
+	level := slog.LevelInfo
+	if c.Logging.Level != "" {
+		var err error
+		level, err = parseLevel(c.Logging.Level)
+		if err != nil {
+			return err
+		}
+	}
+	byName := map[string]slog.Level{}
+	for name, s := range c.Logging.Levels {
+		l, err := parseLevel(s)
+		if err != nil {
+			return err
+		}
+		byName[name] = l
+	}
+
+	m.limiter.mu.Lock()
+	m.limiter.burst = c.Logging.ErrorLimit.Burst
+	if c.Logging.ErrorLimit.Window > 0 {
+		m.limiter.window = c.Logging.ErrorLimit.Window
+	}
+	m.limiter.mu.Unlock()
+
+	m.levels.mu.Lock()
+	defer m.levels.mu.Unlock()
+	m.levels.level = level
+	m.levels.byName = byName
+	return nil
+}
+
+// SetLevel sets the minimum log level for the named logger, or globally if
+// name is empty. An empty level removes the level of the named logger.
+func (m *Module) SetLevel(name, level string) error {
+	if name != "" && level == "" {
+		m.levels.mu.Lock()
+		defer m.levels.mu.Unlock()
+		delete(m.levels.byName, name)
+		return nil
+	}
+	l, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	m.levels.mu.Lock()
+	defer m.levels.mu.Unlock()
+	if name == "" {
+		m.levels.level = l
+	} else {
+		m.levels.byName[name] = l
+	}
+	return nil
+}
+
+// Levels returns the global minimum log level, and the levels by logger name
+func (m *Module) Levels() (string, map[string]string) {
+	m.levels.mu.RLock()
+	defer m.levels.mu.RUnlock()
+	byName := map[string]string{}
+	for name, l := range m.levels.byName {
+		byName[name] = levelName(l)
+	}
+	return levelName(m.levels.level), byName
+}
+
+// Enabled returns true if the named logger, or the root logger if name is
+// empty, logs messages at level.
+func (m *Module) Enabled(name string, level slog.Level) bool {
+	return m.levels.enabled(name, level)
+}
+
+// LevelHandler returns a handler for an admin endpoint which shows the log
+// levels on GET, and changes them on POST or PUT with the `level` and an
+// optional logger `name` form values, e.g. `level=debug&name=migrate`.
+// It is not registered by default, and must be protected by the app.
+func (m *Module) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPost, http.MethodPut:
+			name, level := req.FormValue("name"), req.FormValue("level")
+			err := m.SetLevel(name, level)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			m.Infof("logger: level set to %q for %q", level, name)
+		default:
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		level, byName := m.Levels()
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(map[string]any{"level": level, "levels": byName})
+	})
+}
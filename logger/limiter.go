@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/octavore/nagax/util/errors"
+)
+
+// errorLimiter allows the first burst errors for each key in a window, and
+// counts the rest so they can be summarized at the end of the window.
+type errorLimiter struct {
+	mu     sync.Mutex
+	burst  int // 0 allows all errors
+	window time.Duration
+	counts map[string]int
+}
+
+// errorKey returns the error type and the top stack location of err, or its
+// message if it has no stack, e.g. "*errors.errorString at pkg/file.go|12".
+func errorKey(err error) string {
+	if loc := errors.Location(err); loc != "" {
+		return errors.TypeName(err) + " at " + loc
+	}
+	return fmt.Sprintf("%s %q", errors.TypeName(err), err.Error())
+}
+
+// allow returns false if the error has been seen more than burst times in
+// the current window
+func (l *errorLimiter) allow(err error) bool {
+	if l == nil || err == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.burst <= 0 {
+		return true
+	}
+	key := errorKey(err)
+	l.counts[key]++
+	return l.counts[key] <= l.burst
+}
+
+// allowKV is like allow for the first error value of kv
+func (l *errorLimiter) allowKV(kv []any) bool {
+	for i := 1; i < len(kv); i += 2 {
+		if err, ok := kv[i].(error); ok {
+			return l.allow(err)
+		}
+	}
+	return true
+}
+
+// reset starts a new window, and returns the number of suppressed errors by key
+func (l *errorLimiter) reset() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	suppressed := map[string]int{}
+	for key, n := range l.counts {
+		if n > l.burst {
+			suppressed[key] = n - l.burst
+		}
+	}
+	l.counts = map[string]int{}
+	return suppressed
+}
+
+// AllowError returns false if err should not be logged or reported, because
+// errors with the same type and stack location have been logged too often in
+// the current window. See Config.
+func (m *Module) AllowError(err error) bool {
+	return m.limiter.allow(err)
+}
+
+// runLimiter logs a summary of suppressed errors at the end of each window
+func (m *Module) runLimiter(stop <-chan struct{}) {
+	m.limiter.mu.Lock()
+	window := m.limiter.window
+	m.limiter.mu.Unlock()
+	if window <= 0 {
+		return
+	}
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.logSuppressed(m.limiter.reset())
+		}
+	}
+}
+
+func (m *Module) logSuppressed(suppressed map[string]int) {
+	keys := make([]string, 0, len(suppressed))
+	for key := range suppressed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		m.log.Warningf("suppressed %s similar errors: %s", formatCount(suppressed[key]), key)
+	}
+}
+
+// formatCount formats n with thousands separators, e.g. 1,204
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+
+	"github.com/octavore/nagax/util/errors"
+)
+
+func TestErrorLimiter(t *testing.T) {
+	l := &errorLimiter{burst: 2, window: time.Minute, counts: map[string]int{}}
+	newErr := func() error { return errors.New("db down") }
+	for i := 0; i < 5; i++ {
+		test.Eq(t, i < 2, l.allow(newErr()))
+	}
+	test.True(t, l.allow(errors.New("other line")))
+	test.True(t, l.allow(fmt.Errorf("no stack 1")))
+	test.True(t, l.allow(fmt.Errorf("no stack 2")))
+
+	test.Eq(t, map[string]int{
+		"*errors.errorString at github.com/octavore/nagax/logger/limiter_test.go|17": 3,
+	}, l.reset())
+	test.True(t, l.allow(newErr()))
+
+	l.burst = 0
+	for i := 0; i < 5; i++ {
+		test.True(t, l.allow(newErr()))
+	}
+}
+
+func TestDefaultLoggerLimit(t *testing.T) {
+	m := &Module{
+		levels:  &levels{level: slog.LevelInfo, byName: map[string]slog.Level{}},
+		limiter: &errorLimiter{burst: 1, window: time.Minute, counts: map[string]int{}},
+	}
+	buf := &bytes.Buffer{}
+	d := &DefaultLogger{levels: m.levels, limiter: m.limiter}
+	d.SetOutput(buf)
+	m.log = d
+
+	for i := 0; i < 1205; i++ {
+		d.Error(errors.New("db down"))
+	}
+	m.logSuppressed(m.limiter.reset())
+	test.Eq(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+	test.StrContains(t, buf.String(), "[ERROR] db down\n")
+	test.StrContains(t, buf.String(), "[WARN] suppressed 1,204 similar errors: *errors.errorString at github.com/octavore/nagax/logger/limiter_test.go|47\n")
+}
+
+func TestFormatCount(t *testing.T) {
+	test.Eq(t, "0", formatCount(0))
+	test.Eq(t, "999", formatCount(999))
+	test.Eq(t, "1,204", formatCount(1204))
+	test.Eq(t, "12,345,678", formatCount(12345678))
+}
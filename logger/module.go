@@ -3,72 +3,223 @@ package logger
 import (
 	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/octavore/naga/service"
 )
 
+// Logger is the interface for logging. The KV methods log msg with structured
+// key-value pairs, e.g. InfoKV(ctx, "request", "path", "/", "status", 200).
 type Logger interface {
+	Debug(args ...any)
+	Debugf(format string, args ...any)
+	DebugCtx(ctx context.Context, args ...any)
+	DebugKV(ctx context.Context, msg string, kv ...any)
+
 	Info(args ...any)
 	Infof(format string, args ...any)
 	InfoCtx(ctx context.Context, args ...any)
+	InfoKV(ctx context.Context, msg string, kv ...any)
 
 	Warning(args ...any)
 	Warningf(format string, args ...any)
 	WarningCtx(ctx context.Context, args ...any)
+	WarningKV(ctx context.Context, msg string, kv ...any)
 
 	Error(args ...any)
 	Errorf(format string, args ...any)
 	ErrorCtx(ctx context.Context, args ...any)
+	ErrorKV(ctx context.Context, msg string, kv ...any)
+}
+
+// DefaultLogger logs to Handler. If Handler is nil, it prints lines in the
+// format set with SetFormat, by default `[INFO] msg` with the standard log
+// package.
+type DefaultLogger struct {
+	Handler slog.Handler
+
+	mu      sync.RWMutex
+	levels  *levels       // minimum levels, all levels are logged if nil
+	limiter *errorLimiter // limits repeated errors, if set
+	format  string        // FormatText or FormatJSON
+	out     io.Writer     // defaults to the standard logger output, or os.Stderr
+	file    io.Writer     // optional second output, see SetFileOutput
+	output  slog.Handler  // handler for format, out and file
+}
+
+func (d *DefaultLogger) handler() slog.Handler {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	switch {
+	case d.Handler != nil:
+		return d.Handler
+	case d.output != nil:
+		return d.output
+	}
+	return &textHandler{}
 }
 
-type DefaultLogger struct{}
+func (d *DefaultLogger) log(ctx context.Context, level slog.Level, msg string, kv ...any) {
+	h := d.handler()
+	name := Name(ctx)
+	if !d.levels.enabled(name, level) || !h.Enabled(ctx, level) {
+		return
+	}
+	if level >= slog.LevelError && !d.limiter.allowKV(kv) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	if name != "" {
+		r.AddAttrs(slog.String("logger", name))
+	}
+	r.Add(Fields(ctx)...)
+	r.Add(kv...)
+	_ = h.Handle(ctx, r)
+}
+
+// logArgs logs args as the message. A single error argument is also added as
+// the "error" attribute, so its type and location can be logged.
+func (d *DefaultLogger) logArgs(ctx context.Context, level slog.Level, args []any) {
+	if len(args) == 1 {
+		if err, ok := args[0].(error); ok {
+			d.log(ctx, level, fmt.Sprint(err), "error", err)
+			return
+		}
+	}
+	d.log(ctx, level, fmt.Sprint(args...))
+}
+
+func (d *DefaultLogger) Debug(args ...any) {
+	d.logArgs(context.Background(), slog.LevelDebug, args)
+}
+
+func (d *DefaultLogger) Debugf(format string, args ...any) {
+	d.log(context.Background(), slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (d *DefaultLogger) DebugCtx(ctx context.Context, args ...any) {
+	d.logArgs(ctx, slog.LevelDebug, args)
+}
+
+func (d *DefaultLogger) DebugKV(ctx context.Context, msg string, kv ...any) {
+	d.log(ctx, slog.LevelDebug, msg, kv...)
+}
 
 func (d *DefaultLogger) Info(args ...any) {
-	log.Println("[INFO]", fmt.Sprint(args...))
+	d.logArgs(context.Background(), slog.LevelInfo, args)
 }
 
 func (d *DefaultLogger) Infof(format string, args ...any) {
-	log.Printf("[INFO] "+format, args...)
+	d.log(context.Background(), slog.LevelInfo, fmt.Sprintf(format, args...))
 }
 
 func (d *DefaultLogger) InfoCtx(ctx context.Context, args ...any) {
-	d.Info(args...)
+	d.logArgs(ctx, slog.LevelInfo, args)
+}
+
+func (d *DefaultLogger) InfoKV(ctx context.Context, msg string, kv ...any) {
+	d.log(ctx, slog.LevelInfo, msg, kv...)
 }
 
 func (d *DefaultLogger) Warning(args ...any) {
-	log.Println("[WARN]", fmt.Sprint(args...))
+	d.logArgs(context.Background(), slog.LevelWarn, args)
 }
 
 func (d *DefaultLogger) Warningf(format string, args ...any) {
-	log.Printf("[WARN] "+format, args...)
+	d.log(context.Background(), slog.LevelWarn, fmt.Sprintf(format, args...))
 }
 
 func (d *DefaultLogger) WarningCtx(ctx context.Context, args ...any) {
-	d.Warning(args...)
+	d.logArgs(ctx, slog.LevelWarn, args)
+}
+
+func (d *DefaultLogger) WarningKV(ctx context.Context, msg string, kv ...any) {
+	d.log(ctx, slog.LevelWarn, msg, kv...)
 }
 
 func (d *DefaultLogger) Error(args ...any) {
-	log.Println("[ERROR]", fmt.Sprint(args...))
+	d.logArgs(context.Background(), slog.LevelError, args)
 }
 
 func (d *DefaultLogger) Errorf(format string, args ...any) {
-	log.Printf("[ERROR] "+format, args...)
+	d.log(context.Background(), slog.LevelError, fmt.Sprintf(format, args...))
 }
 
 func (d *DefaultLogger) ErrorCtx(ctx context.Context, args ...any) {
-	d.Error(args...)
+	d.logArgs(ctx, slog.LevelError, args)
+}
+
+func (d *DefaultLogger) ErrorKV(ctx context.Context, msg string, kv ...any) {
+	d.log(ctx, slog.LevelError, msg, kv...)
 }
 
 var _ service.Module = &Module{}
 
 type Module struct {
 	Logger
+
+	defaultLogger *DefaultLogger
+	levels        *levels
+	limiter       *errorLimiter
+	file          *RotatingFile // set by SetConfig
+	log           Logger        // named "logger"
+	stop          chan struct{}
+
+	// set before Setup, and applied to the default logger in Setup
+	handler slog.Handler
+	format  string
+	out     io.Writer
 }
 
 func (m *Module) Init(c *service.Config) {
 	c.Setup = func() error {
-		m.Logger = &DefaultLogger{}
+		m.levels = &levels{level: slog.LevelInfo, byName: map[string]slog.Level{}}
+		m.limiter = &errorLimiter{burst: 10, window: time.Minute, counts: map[string]int{}}
+		m.defaultLogger = &DefaultLogger{Handler: m.handler, levels: m.levels, limiter: m.limiter}
+		if m.format != "" || m.out != nil {
+			m.defaultLogger.format, m.defaultLogger.out = m.format, m.out
+			m.defaultLogger.updateOutput()
+		}
+		m.Logger = m.defaultLogger
+		m.log = m.Named("logger")
 		return nil
 	}
+
+	c.Start = func() {
+		m.stop = make(chan struct{})
+		go m.runLimiter(m.stop)
+		if m.file != nil {
+			go m.reopenOnSIGHUP(m.file, m.stop)
+		}
+	}
+
+	c.Stop = func() {
+		if m.stop != nil {
+			close(m.stop)
+			m.stop = nil
+		}
+		m.closeFile()
+	}
+}
+
+// SetHandler sets the slog.Handler which the default logger writes to,
+// instead of the configured format. Wrappers installed by other modules,
+// e.g. bugsnag, are kept. It can be called before Setup.
+func (m *Module) SetHandler(h slog.Handler) {
+	m.handler = h
+	if m.defaultLogger == nil {
+		return // applied in Setup
+	}
+	m.defaultLogger.mu.Lock()
+	defer m.defaultLogger.mu.Unlock()
+	m.defaultLogger.Handler = h
+}
+
+// Slog returns a *slog.Logger which logs through m.Logger, including any
+// wrappers installed by other modules.
+func (m *Module) Slog() *slog.Logger {
+	return slog.New(&pipelineHandler{module: m})
 }
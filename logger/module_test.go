@@ -0,0 +1,257 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/octavore/naga/service"
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/octavore/nagax/logger"
+	nerrors "github.com/octavore/nagax/util/errors"
+	"github.com/octavore/nagax/util/memlogger"
+)
+
+type ctxKey struct{}
+
+// ctxHandler adds the ctxKey value of the context to each record
+type ctxHandler struct {
+	slog.Handler
+}
+
+func (h *ctxHandler) Handle(ctx context.Context, r slog.Record) error {
+	if v := ctx.Value(ctxKey{}); v != nil {
+		r.AddAttrs(slog.Any("ctx", v))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestSetHandler(t *testing.T) {
+	m, stop := service.New(&logger.Module{}).StartForTest()
+	defer stop()
+
+	buf := &bytes.Buffer{}
+	m.SetHandler(&ctxHandler{slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "abc")
+	m.Info("hello")
+	m.WarningKV(ctx, "world", "count", 2)
+	m.Slog().ErrorContext(ctx, "failed", "err", errors.New("bad"))
+	test.Eq(t, "level=INFO msg=hello\n"+
+		"level=WARN msg=world count=2 ctx=abc\n"+
+		"level=ERROR msg=failed err=bad ctx=abc\n", buf.String())
+}
+
+func TestSlogPipeline(t *testing.T) {
+	m, stop := service.New(&logger.Module{}).StartForTest()
+	defer stop()
+
+	mem := &memlogger.MemoryLogger{}
+	m.Logger = mem
+	l := m.Slog().With("a", 1).WithGroup("g")
+	l.Info("hello", "b", "x y")
+	l.Warn("careful")
+	l.Error("failed", slog.Group("err", "msg", "bad"))
+
+	must.Eq(t, []string{`hello a=1 g.b="x y"`}, mem.Infos)
+	must.Eq(t, []string{`careful a=1`}, mem.Warnings)
+	must.Eq(t, []string{`failed a=1 g.err.msg=bad`}, mem.Errors)
+}
+
+func TestWithFields(t *testing.T) {
+	m, stop := service.New(&logger.Module{}).StartForTest()
+	defer stop()
+
+	buf := &bytes.Buffer{}
+	m.SetHandler(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	ctx := logger.WithFields(context.Background(), "request_id", "abc")
+	ctx1 := logger.WithFields(ctx, "user_id", 1)
+	ctx2 := logger.WithFields(ctx, "user_id", 2)
+	test.Eq(t, []any{"request_id", "abc", "user_id", 1}, logger.Fields(ctx1))
+	test.Eq(t, []any{"request_id", "abc", "user_id", 2}, logger.Fields(ctx2))
+
+	m.InfoCtx(ctx1, "hello")
+	m.ErrorKV(ctx2, "failed", "n", 3)
+	test.Eq(t, "level=INFO msg=hello request_id=abc user_id=1\n"+
+		"level=ERROR msg=failed request_id=abc user_id=2 n=3\n", buf.String())
+}
+
+func TestLevels(t *testing.T) {
+	m, stop := service.New(&logger.Module{}).StartForTest()
+	defer stop()
+
+	buf := &bytes.Buffer{}
+	m.SetHandler(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	m.Debug("hidden")
+	must.NoError(t, m.SetLevel("", "debug"))
+	m.Debugf("shown %d", 1)
+	must.NoError(t, m.SetLevel("", "warning"))
+	m.Info("hidden")
+	m.Warning("shown")
+	test.Eq(t, "level=DEBUG msg=\"shown 1\"\nlevel=WARN msg=shown\n", buf.String())
+
+	test.Error(t, m.SetLevel("", "verbose"))
+	must.NoError(t, m.SetLevel("migrate", "debug"))
+	test.True(t, m.Enabled("migrate", slog.LevelDebug))
+	test.False(t, m.Enabled("router", slog.LevelInfo))
+
+	rr := httptest.NewRecorder()
+	m.LevelHandler().ServeHTTP(rr, httptest.NewRequest("POST", "/?name=router&level=info", nil))
+	test.Eq(t, 200, rr.Code)
+	test.EqJSON(t, `{"level": "warn", "levels": {"migrate": "debug", "router": "info"}}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/", strings.NewReader("name=migrate"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	m.LevelHandler().ServeHTTP(rr, req)
+	test.Eq(t, 200, rr.Code)
+	level, byName := m.Levels()
+	test.Eq(t, "warn", level)
+	test.Eq(t, map[string]string{"router": "info"}, byName)
+
+	rr = httptest.NewRecorder()
+	m.LevelHandler().ServeHTTP(rr, httptest.NewRequest("POST", "/?level=loud", nil))
+	test.Eq(t, 400, rr.Code)
+}
+
+func TestNamed(t *testing.T) {
+	m, stop := service.New(&logger.Module{}).StartForTest()
+	defer stop()
+
+	buf := &bytes.Buffer{}
+	m.SetHandler(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	migrate := m.Named("migrate")
+	graceful := m.Named("graceful")
+	must.NoError(t, m.SetLevel("migrate", "debug"))
+	must.NoError(t, m.SetLevel("graceful", "warn"))
+
+	migrate.Debugf("applied %d", 2)
+	graceful.Info("tick")
+	graceful.ErrorKV(context.Background(), "failed", "id", "poll")
+	m.Debug("hidden")
+	test.Eq(t, "level=DEBUG msg=\"applied 2\" logger=migrate\n"+
+		"level=ERROR msg=failed logger=graceful id=poll\n", buf.String())
+
+	// named loggers log through wrappers set after they were created
+	mem := &memlogger.MemoryLogger{}
+	m.Logger = mem
+	migrate.InfoCtx(logger.WithFields(context.Background(), "db", "test"), "migrated")
+	test.Eq(t, []string{"migrate: migrated db=test"}, mem.Infos)
+}
+
+func TestFormatJSON(t *testing.T) {
+	m, stop := service.New(&logger.Module{}).StartForTest()
+	defer stop()
+
+	buf := &bytes.Buffer{}
+	m.SetOutput(buf)
+	must.NoError(t, m.SetFormat(logger.FormatJSON))
+	test.Error(t, m.SetFormat("xml"))
+
+	ctx := logger.WithFields(context.Background(), "request_id", "abc")
+	m.Named("router").ErrorCtx(ctx, nerrors.New("bad"))
+	m.Info("hello")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	must.SliceLen(t, 2, lines)
+	line := map[string]any{}
+	must.NoError(t, json.Unmarshal(lines[0], &line))
+	test.MapContainsKey(t, line, "time")
+	delete(line, "time")
+	test.Eq(t, map[string]any{
+		"level":       "ERROR",
+		"msg":         "bad",
+		"logger":      "router",
+		"request_id":  "abc",
+		"error":       "bad",
+		"error_type":  "*errors.errorString",
+		"error_frame": "github.com/octavore/nagax/logger_test/module_test.go:191",
+	}, line)
+	test.StrContains(t, string(lines[1]), `"level":"INFO","msg":"hello"`)
+}
+
+func TestFormatText(t *testing.T) {
+	m, stop := service.New(&logger.Module{}).StartForTest()
+	defer stop()
+
+	buf := &bytes.Buffer{}
+	m.SetOutput(buf)
+	m.Named("router").ErrorKV(context.Background(), "failed", "error", errors.New("bad"))
+	m.Error(errors.New("bad"))
+	test.StrContains(t, buf.String(), "[ERROR] router: failed error=bad\n")
+	test.StrContains(t, buf.String(), "[ERROR] bad\n")
+}
+
+func TestMulti(t *testing.T) {
+	mem1, mem2 := &memlogger.MemoryLogger{}, &memlogger.MemoryLogger{}
+	l := logger.Multi(mem1, mem2)
+	l.Infof("hello %d", 1)
+	l.ErrorKV(context.Background(), "failed", "id", 2)
+	for _, mem := range []*memlogger.MemoryLogger{mem1, mem2} {
+		test.Eq(t, []string{"hello 1"}, mem.Infos)
+		test.Eq(t, []string{"failed id=2"}, mem.Errors)
+	}
+}
+
+func TestSetHandlerBeforeSetup(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := &logger.Module{}
+	m.SetHandler(slog.NewTextHandler(buf, nil))
+
+	m, stop := service.New(m).StartForTest()
+	defer stop()
+	m.Info("hello")
+	test.StrContains(t, buf.String(), "level=INFO msg=hello")
+}
+
+func TestSetFormatBeforeSetup(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := &logger.Module{}
+	must.NoError(t, m.SetFormat(logger.FormatJSON))
+	must.Error(t, m.SetFormat("xml"))
+	m.SetOutput(buf)
+
+	m, stop := service.New(m).StartForTest()
+	defer stop()
+	m.Info("hello")
+	test.StrContains(t, buf.String(), `"msg":"hello"`)
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Multi returns a Logger which logs to all of loggers, e.g. to combine the
+// default logger with a Logger for an external service.
+func Multi(loggers ...Logger) Logger {
+	return multiLogger(loggers)
+}
+
+type multiLogger []Logger
+
+func (m multiLogger) Debug(args ...any) {
+	for _, l := range m {
+		l.Debug(args...)
+	}
+}
+
+func (m multiLogger) Debugf(format string, args ...any) {
+	for _, l := range m {
+		l.Debugf(format, args...)
+	}
+}
+
+func (m multiLogger) DebugCtx(ctx context.Context, args ...any) {
+	for _, l := range m {
+		l.DebugCtx(ctx, args...)
+	}
+}
+
+func (m multiLogger) DebugKV(ctx context.Context, msg string, kv ...any) {
+	for _, l := range m {
+		l.DebugKV(ctx, msg, kv...)
+	}
+}
+
+func (m multiLogger) Info(args ...any) {
+	for _, l := range m {
+		l.Info(args...)
+	}
+}
+
+func (m multiLogger) Infof(format string, args ...any) {
+	for _, l := range m {
+		l.Infof(format, args...)
+	}
+}
+
+func (m multiLogger) InfoCtx(ctx context.Context, args ...any) {
+	for _, l := range m {
+		l.InfoCtx(ctx, args...)
+	}
+}
+
+func (m multiLogger) InfoKV(ctx context.Context, msg string, kv ...any) {
+	for _, l := range m {
+		l.InfoKV(ctx, msg, kv...)
+	}
+}
+
+func (m multiLogger) Warning(args ...any) {
+	for _, l := range m {
+		l.Warning(args...)
+	}
+}
+
+func (m multiLogger) Warningf(format string, args ...any) {
+	for _, l := range m {
+		l.Warningf(format, args...)
+	}
+}
+
+func (m multiLogger) WarningCtx(ctx context.Context, args ...any) {
+	for _, l := range m {
+		l.WarningCtx(ctx, args...)
+	}
+}
+
+func (m multiLogger) WarningKV(ctx context.Context, msg string, kv ...any) {
+	for _, l := range m {
+		l.WarningKV(ctx, msg, kv...)
+	}
+}
+
+func (m multiLogger) Error(args ...any) {
+	for _, l := range m {
+		l.Error(args...)
+	}
+}
+
+func (m multiLogger) Errorf(format string, args ...any) {
+	for _, l := range m {
+		l.Errorf(format, args...)
+	}
+}
+
+func (m multiLogger) ErrorCtx(ctx context.Context, args ...any) {
+	for _, l := range m {
+		l.ErrorCtx(ctx, args...)
+	}
+}
+
+func (m multiLogger) ErrorKV(ctx context.Context, msg string, kv ...any) {
+	for _, l := range m {
+		l.ErrorKV(ctx, msg, kv...)
+	}
+}
+
+// multiHandler is a slog.Handler which writes to all of its handlers, used
+// by the DefaultLogger for the file output.
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range m {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		err := h.Handle(ctx, r.Clone())
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type nameKey struct{}
+
+// withName returns a copy of ctx with the logger name
+func withName(ctx context.Context, name string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, nameKey{}, name)
+}
+
+// Name returns the name of the named logger which ctx is logged with, or ""
+// for the root logger. Loggers should print it with each line.
+func Name(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(nameKey{}).(string)
+	return name
+}
+
+// Named returns a Logger which tags each line with name, e.g. "migrate", and
+// only logs messages at or above the level set for name (see SetLevel), or
+// the global level. It logs through m.Logger, so it may be created before
+// other modules wrap m.Logger.
+func (m *Module) Named(name string) Logger {
+	return &namedLogger{module: m, name: name}
+}
+
+type namedLogger struct {
+	module *Module
+	name   string
+}
+
+func (n *namedLogger) enabled(level slog.Level) bool {
+	return n.module.levels.enabled(n.name, level)
+}
+
+func (n *namedLogger) Debug(args ...any) {
+	n.DebugCtx(context.Background(), args...)
+}
+
+func (n *namedLogger) Debugf(format string, args ...any) {
+	n.DebugCtx(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (n *namedLogger) DebugCtx(ctx context.Context, args ...any) {
+	if n.enabled(slog.LevelDebug) {
+		n.module.Logger.DebugCtx(withName(ctx, n.name), args...)
+	}
+}
+
+func (n *namedLogger) DebugKV(ctx context.Context, msg string, kv ...any) {
+	if n.enabled(slog.LevelDebug) {
+		n.module.Logger.DebugKV(withName(ctx, n.name), msg, kv...)
+	}
+}
+
+func (n *namedLogger) Info(args ...any) {
+	n.InfoCtx(context.Background(), args...)
+}
+
+func (n *namedLogger) Infof(format string, args ...any) {
+	n.InfoCtx(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (n *namedLogger) InfoCtx(ctx context.Context, args ...any) {
+	if n.enabled(slog.LevelInfo) {
+		n.module.Logger.InfoCtx(withName(ctx, n.name), args...)
+	}
+}
+
+func (n *namedLogger) InfoKV(ctx context.Context, msg string, kv ...any) {
+	if n.enabled(slog.LevelInfo) {
+		n.module.Logger.InfoKV(withName(ctx, n.name), msg, kv...)
+	}
+}
+
+func (n *namedLogger) Warning(args ...any) {
+	n.WarningCtx(context.Background(), args...)
+}
+
+func (n *namedLogger) Warningf(format string, args ...any) {
+	n.WarningCtx(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (n *namedLogger) WarningCtx(ctx context.Context, args ...any) {
+	if n.enabled(slog.LevelWarn) {
+		n.module.Logger.WarningCtx(withName(ctx, n.name), args...)
+	}
+}
+
+func (n *namedLogger) WarningKV(ctx context.Context, msg string, kv ...any) {
+	if n.enabled(slog.LevelWarn) {
+		n.module.Logger.WarningKV(withName(ctx, n.name), msg, kv...)
+	}
+}
+
+func (n *namedLogger) Error(args ...any) {
+	n.ErrorCtx(context.Background(), args...)
+}
+
+func (n *namedLogger) Errorf(format string, args ...any) {
+	n.ErrorCtx(context.Background(), fmt.Sprintf(format, args...))
+}
+
+func (n *namedLogger) ErrorCtx(ctx context.Context, args ...any) {
+	if n.enabled(slog.LevelError) {
+		n.module.Logger.ErrorCtx(withName(ctx, n.name), args...)
+	}
+}
+
+func (n *namedLogger) ErrorKV(ctx context.Context, msg string, kv ...any) {
+	if n.enabled(slog.LevelError) {
+		n.module.Logger.ErrorKV(withName(ctx, n.name), msg, kv...)
+	}
+}
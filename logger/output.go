@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path"
+
+	goerrors "github.com/go-errors/errors"
+)
+
+// Output formats of the DefaultLogger
+const (
+	FormatText = "text" // `[INFO] name: msg key=value`, the default
+	FormatJSON = "json" // one JSON object per line
+)
+
+// SetFormat sets the output format, FormatText or FormatJSON
+func (d *DefaultLogger) SetFormat(format string) error {
+	if format != FormatText && format != FormatJSON {
+		return fmt.Errorf("logger: invalid format %q", format)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.format = format
+	d.updateOutput()
+	return nil
+}
+
+// SetOutput sets the writer for both output formats
+func (d *DefaultLogger) SetOutput(w io.Writer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.out = w
+	d.updateOutput()
+}
+
+// SetFileOutput sets a second writer which lines are also written to, e.g. a
+// RotatingFile. It is removed if w is nil.
+func (d *DefaultLogger) SetFileOutput(w io.Writer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.file = w
+	d.updateOutput()
+}
+
+// updateOutput creates the handler for the format and writers
+func (d *DefaultLogger) updateOutput() {
+	d.output = formatHandler(d.format, d.out)
+	if d.file != nil {
+		d.output = multiHandler{d.output, formatHandler(d.format, d.file)}
+	}
+}
+
+// formatHandler returns the handler for format which writes to w. The text
+// format uses the standard logger if w is nil.
+func formatHandler(format string, w io.Writer) slog.Handler {
+	switch {
+	case format == FormatJSON && w == nil:
+		return newJSONHandler(os.Stderr)
+	case format == FormatJSON:
+		return newJSONHandler(w)
+	case w != nil:
+		return &textHandler{logger: log.New(w, "", log.LstdFlags)}
+	}
+	return &textHandler{}
+}
+
+// SetFormat sets the output format of the default logger. It can be called
+// before Setup.
+func (m *Module) SetFormat(format string) error {
+	if format != FormatText && format != FormatJSON {
+		return fmt.Errorf("logger: invalid format %q", format)
+	}
+	m.format = format
+	if m.defaultLogger == nil {
+		return nil // applied in Setup
+	}
+	return m.defaultLogger.SetFormat(format)
+}
+
+// SetOutput sets the writer of the default logger, e.g. os.Stdout. It can be
+// called before Setup.
+func (m *Module) SetOutput(w io.Writer) {
+	m.out = w
+	if m.defaultLogger == nil {
+		return // applied in Setup
+	}
+	m.defaultLogger.SetOutput(w)
+}
+
+// jsonHandler writes records as JSON lines with slog.JSONHandler. Error
+// values are written as their message, and for errors with a stack from
+// go-errors, with their type and top stack frame, e.g.
+// `"error": "bad", "error_type": "*fs.PathError", "error_frame": "pkg/file.go:12"`.
+type jsonHandler struct {
+	slog.Handler
+}
+
+func newJSONHandler(w io.Writer) *jsonHandler {
+	// levels are filtered by the DefaultLogger
+	return &jsonHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})}
+}
+
+func (h *jsonHandler) Handle(ctx context.Context, r slog.Record) error {
+	r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		r2.AddAttrs(errorAttrs(a)...)
+		return true
+	})
+	return h.Handler.Handle(ctx, r2)
+}
+
+func (h *jsonHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := []slog.Attr{}
+	for _, a := range attrs {
+		expanded = append(expanded, errorAttrs(a)...)
+	}
+	return &jsonHandler{h.Handler.WithAttrs(expanded)}
+}
+
+func (h *jsonHandler) WithGroup(name string) slog.Handler {
+	return &jsonHandler{h.Handler.WithGroup(name)}
+}
+
+// errorAttrs expands a with an error value into its message, type and frame
+func errorAttrs(a slog.Attr) []slog.Attr {
+	err, ok := a.Value.Any().(error)
+	if !ok {
+		return []slog.Attr{a}
+	}
+	attrs := []slog.Attr{slog.String(a.Key, err.Error())}
+	var errWithStack *goerrors.Error
+	if errors.As(err, &errWithStack) {
+		attrs = append(attrs, slog.String(a.Key+"_type", fmt.Sprintf("%T", errWithStack.Err)))
+		if frames := errWithStack.StackFrames(); len(frames) > 0 {
+			f := frames[0]
+			attrs = append(attrs, slog.String(a.Key+"_frame", fmt.Sprintf("%s/%s:%d", f.Package, path.Base(f.File), f.LineNumber)))
+		}
+	}
+	return attrs
+}
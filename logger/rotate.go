@@ -0,0 +1,291 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeFormat is the timestamp suffix of rotated files
+const backupTimeFormat = "20060102T150405.000"
+
+// RotatingFile is an io.Writer which appends to the file at Path, and
+// rotates it when it grows larger than MaxSize or older than MaxAge. Rotated
+// files are renamed with a timestamp suffix, e.g. app.log.20060102T150405.000,
+// with a counter added if the name is taken, e.g. app.log.20060102T150405.000-1,
+// and compressed if Compress is set.
+type RotatingFile struct {
+	Path       string
+	MaxSize    int64         // in bytes, 0 for no limit
+	MaxAge     time.Duration // 0 for no limit
+	MaxBackups int           // rotated files to keep, 0 keeps all
+	Compress   bool          // gzip rotated files
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	opened  time.Time
+	closed  bool
+	pending sync.WaitGroup // compression of rotated files
+}
+
+// Write implements io.Writer, opening or rotating the file if needed
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		err := f.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+	tooLarge := f.MaxSize > 0 && f.size+int64(len(p)) > f.MaxSize
+	tooOld := f.MaxAge > 0 && time.Since(f.opened) >= f.MaxAge
+	if f.size > 0 && (tooLarge || tooOld) {
+		err := f.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Reopen closes the file so that the next write opens it again, e.g. after
+// it has been moved by an external tool.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closeFile()
+}
+
+// Rotate the file now
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		err := f.open()
+		if err != nil {
+			return err
+		}
+	}
+	return f.rotate()
+}
+
+// Close flushes and closes the file, and waits for rotated files to be
+// compressed. Writes after Close fail.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	f.closed = true
+	err := f.closeFile()
+	f.mu.Unlock()
+	f.pending.Wait()
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	err := os.MkdirAll(filepath.Dir(f.Path), 0o755)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size, f.opened = file, info.Size(), time.Now()
+	return nil
+}
+
+func (f *RotatingFile) closeFile() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Sync()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	f.file = nil
+	return err
+}
+
+// rotate renames the current file and opens a new one
+func (f *RotatingFile) rotate() error {
+	err := f.closeFile()
+	if err != nil {
+		return err
+	}
+	rotated := backupName(f.Path, time.Now())
+	err = os.Rename(f.Path, rotated)
+	if err != nil {
+		return err
+	}
+	err = f.open()
+	if err != nil {
+		return err
+	}
+
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+		if f.Compress {
+			_ = compressFile(rotated)
+		}
+		f.removeBackups()
+	}()
+	return nil
+}
+
+// backupName returns an unused name for the rotated file, so that rotations
+// in the same millisecond do not overwrite each other
+func backupName(path string, now time.Time) string {
+	name := path + "." + now.Format(backupTimeFormat)
+	rotated := name
+	for i := 1; exists(rotated) || exists(rotated+".gz"); i++ {
+		rotated = name + "-" + strconv.Itoa(i)
+	}
+	return rotated
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// removeBackups removes the oldest rotated files beyond MaxBackups
+func (f *RotatingFile) removeBackups() {
+	if f.MaxBackups <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(filepath.Dir(f.Path))
+	if err != nil {
+		return
+	}
+	// compressed files are removed once compressed, so a backup may exist
+	// with and without the .gz suffix
+	backups := []backup{}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		b, ok := parseBackup(filepath.Base(f.Path), e.Name())
+		if ok && !seen[b.name] {
+			seen[b.name] = true
+			backups = append(backups, b)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { // oldest first
+		if !backups[i].time.Equal(backups[j].time) {
+			return backups[i].time.Before(backups[j].time)
+		}
+		return backups[i].counter < backups[j].counter
+	})
+	dir := filepath.Dir(f.Path)
+	for i := 0; i < len(backups)-f.MaxBackups; i++ {
+		_ = os.Remove(filepath.Join(dir, backups[i].name))
+		_ = os.Remove(filepath.Join(dir, backups[i].name+".gz"))
+	}
+}
+
+// backup is a rotated file, see backupName
+type backup struct {
+	name    string // without the .gz suffix
+	time    time.Time
+	counter int
+}
+
+// parseBackup returns the backup of the log file base with the file name, and
+// false if name is not a rotated file, e.g. app.log.lock
+func parseBackup(base, name string) (backup, bool) {
+	name = strings.TrimSuffix(name, ".gz")
+	suffix, ok := strings.CutPrefix(name, base+".")
+	if !ok {
+		return backup{}, false
+	}
+	ts, counter, hasCounter := strings.Cut(suffix, "-")
+	b := backup{name: name}
+	if hasCounter {
+		n, err := strconv.Atoi(counter)
+		if err != nil || n < 1 || strconv.Itoa(n) != counter {
+			return backup{}, false
+		}
+		b.counter = n
+	}
+	t, err := time.Parse(backupTimeFormat, ts)
+	if err != nil || t.Format(backupTimeFormat) != ts {
+		return backup{}, false
+	}
+	b.time = t
+	return b, true
+}
+
+// compressFile gzips the file at path to path.gz, and removes it
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
+
+// reopenOnSIGHUP reopens the log file on SIGHUP until stop is closed
+func (m *Module) reopenOnSIGHUP(file *RotatingFile, stop <-chan struct{}) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-sighup:
+			err := file.Reopen()
+			if err != nil {
+				m.log.Errorf("error reopening %s: %v", file.Path, err)
+			}
+		}
+	}
+}
+
+// closeFile flushes and closes the log file on shutdown
+func (m *Module) closeFile() {
+	if m.file == nil {
+		return
+	}
+	m.defaultLogger.SetFileOutput(nil)
+	err := m.file.Close()
+	if err != nil {
+		m.log.Errorf("error closing %s: %v", m.file.Path, err)
+	}
+	m.file = nil
+}
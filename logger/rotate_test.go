@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	f := &RotatingFile{Path: filepath.Join(dir, "log", "app.log"), MaxSize: 10, MaxBackups: 2, Compress: true}
+
+	for _, line := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4\n"} {
+		_, err := f.Write([]byte(line))
+		must.NoError(t, err)
+		time.Sleep(2 * time.Millisecond) // unique timestamp suffixes
+	}
+	must.NoError(t, f.Close())
+	_, err := f.Write([]byte("closed\n"))
+	test.ErrorIs(t, err, os.ErrClosed)
+
+	b, err := os.ReadFile(f.Path)
+	must.NoError(t, err)
+	test.Eq(t, "line 4\n", string(b))
+
+	backups, err := filepath.Glob(f.Path + ".*")
+	must.NoError(t, err)
+	must.SliceLen(t, 2, backups)
+	for i, want := range []string{"line 2\n", "line 3\n"} {
+		test.StrHasSuffix(t, ".gz", backups[i])
+		test.Eq(t, want, readGzip(t, backups[i]))
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	f := &RotatingFile{Path: filepath.Join(dir, "app.log")}
+	defer f.Close()
+
+	_, err := f.Write([]byte("before\n"))
+	must.NoError(t, err)
+	must.NoError(t, os.Rename(f.Path, f.Path+".moved"))
+	must.NoError(t, f.Reopen())
+	_, err = f.Write([]byte("after\n"))
+	must.NoError(t, err)
+
+	b, err := os.ReadFile(f.Path)
+	must.NoError(t, err)
+	test.Eq(t, "after\n", string(b))
+}
+
+func TestFileOutput(t *testing.T) {
+	dir := t.TempDir()
+	m := &Module{defaultLogger: &DefaultLogger{}, levels: &levels{}, limiter: &errorLimiter{}}
+	c := Config{}
+	c.Logging.File.Path = filepath.Join(dir, "app.log")
+	must.NoError(t, m.SetConfig(c))
+
+	console := &strings.Builder{}
+	m.defaultLogger.SetOutput(console)
+	m.defaultLogger.Info("hello")
+	m.closeFile()
+	m.defaultLogger.Info("closed")
+
+	b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	must.NoError(t, err)
+	test.StrHasSuffix(t, "[INFO] hello\n", string(b))
+	test.StrContains(t, console.String(), "[INFO] hello\n")
+	test.StrContains(t, console.String(), "[INFO] closed\n")
+}
+
+func readGzip(t *testing.T, path string) string {
+	file, err := os.Open(path)
+	must.NoError(t, err)
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	must.NoError(t, err)
+	b, err := io.ReadAll(zr)
+	must.NoError(t, err)
+	return string(b)
+}
+
+func TestRotatingFileSameTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	f := &RotatingFile{Path: filepath.Join(dir, "app.log")}
+	defer f.Close()
+
+	for _, line := range []string{"line 1\n", "line 2\n", "line 3\n"} {
+		_, err := f.Write([]byte(line))
+		must.NoError(t, err)
+		must.NoError(t, f.Rotate())
+	}
+	backups, err := filepath.Glob(f.Path + ".*")
+	must.NoError(t, err)
+	test.SliceLen(t, 3, backups)
+}
+
+func TestRotatingFileKeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	f := &RotatingFile{Path: filepath.Join(dir, "app.log"), MaxBackups: 1}
+	others := []string{"app.log.lock", "app.log.gz", "app.log.20060102T150405", "app.log.20060102T150405.000-x"}
+	for _, name := range others {
+		must.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	for _, line := range []string{"line 1\n", "line 2\n", "line 3\n"} {
+		_, err := f.Write([]byte(line))
+		must.NoError(t, err)
+		must.NoError(t, f.Rotate())
+	}
+	must.NoError(t, f.Close())
+
+	for _, name := range others {
+		test.FileExists(t, filepath.Join(dir, name))
+	}
+	backups, err := filepath.Glob(f.Path + ".2*")
+	must.NoError(t, err)
+	backups = slices.DeleteFunc(backups, func(p string) bool {
+		return slices.Contains(others, filepath.Base(p))
+	})
+	must.SliceLen(t, 1, backups)
+	b, err := os.ReadFile(backups[0])
+	must.NoError(t, err)
+	test.Eq(t, "line 3\n", string(b))
+}
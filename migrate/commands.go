@@ -21,14 +21,14 @@ func (m *Module) registerCommands(c *service.Config) {
 			}
 			b, err := m.GetBackend(ctx.Args[0])
 			if err != nil {
-				m.Logger.Error("migrate:", err)
+				m.log.Error(err)
 				return
 			}
 			err = backoff.RetryNotify(b.Migrate, m.backoff, func(err error, duration time.Duration) {
-				m.Logger.Warningf("can't connect to db: %s, will retry in %s", err, duration)
+				m.log.Warningf("can't connect to db: %s, will retry in %s", err, duration)
 			})
 			if err != nil {
-				m.Logger.Error("migrate:", err)
+				m.log.Error(err)
 			}
 		},
 	})
@@ -42,13 +42,13 @@ func (m *Module) registerCommands(c *service.Config) {
 			}
 			b, err := m.GetBackend(ctx.Args[0])
 			if err != nil {
-				m.Logger.Error("migrate:", err)
+				m.log.Error(err)
 				return
 			}
 
 			allMigrations, err := b.migrations().FindMigrations()
 			if err != nil {
-				m.Logger.Error("migrate:", err)
+				m.log.Error(err)
 				return
 			}
 			var unapplied []string
@@ -59,10 +59,10 @@ func (m *Module) registerCommands(c *service.Config) {
 				}
 				return nil
 			}, m.backoff, func(err error, duration time.Duration) {
-				m.Logger.Warningf("can't connect to db: %s, will retry in %s", err, duration)
+				m.log.Warningf("can't connect to db: %s, will retry in %s", err, duration)
 			})
 			if err != nil {
-				m.Logger.Error("migrate:", err)
+				m.log.Error(err)
 			}
 
 			unappliedSet := map[string]bool{}
@@ -90,16 +90,16 @@ func (m *Module) registerCommands(c *service.Config) {
 			dbname := ctx.Args[0]
 			b, err := m.GetBackend(dbname)
 			if err != nil {
-				m.Logger.Error("migrate:", err)
+				m.log.Error(err)
 				return
 			}
 			err = b.Reset()
 			if err != nil {
-				m.Logger.Error("migrate:", err)
+				m.log.Error(err)
 			}
 			err = b.Migrate()
 			if err != nil {
-				m.Logger.Error("migrate:", err)
+				m.log.Error(err)
 			}
 		},
 	})
@@ -22,6 +22,7 @@ type Module struct {
 	DB     *sql.DB
 	Logger *logger.Module
 
+	log             logger.Logger // named "migrate"
 	config          Config
 	migrationSource migrate.MigrationSource
 	backoff         backoff.BackOff
@@ -32,15 +33,15 @@ type Module struct {
 
 // Config for migrate module
 type Config struct {
-	Datasources     map[string]Datasource `json:"datasources"`
-	MigrationsDir   string                `json:"migrations"`
-	MigrationsTable string                `json:"migrations_table"`
+	Datasources     map[string]Datasource `json:"datasources" desc:"datasources by name, e.g. production or test"`
+	MigrationsDir   string                `json:"migrations" desc:"directory containing sql migrations"`
+	MigrationsTable string                `json:"migrations_table" desc:"defaults to schema_migrations"`
 }
 
 // Datasource is parsed from the config
 type Datasource struct {
-	Driver string `json:"driver"`
-	DSN    string `json:"dsn"`
+	Driver string `json:"driver" validate:"required,oneof=postgres mysql sqlite"`
+	DSN    string `json:"dsn" validate:"required"`
 }
 
 // Init the migrate module
@@ -51,6 +52,7 @@ func (m *Module) Init(c *service.Config) {
 	migrate.MigrationDialects["sqlite"] = migrate.MigrationDialects["sqlite3"]
 
 	c.Setup = func() error {
+		m.log = m.Logger.Named("migrate")
 		m.env = c.Env()
 		m.backoff = &backoff.StopBackOff{}
 		err := m.Config.ReadConfig(&m.config)
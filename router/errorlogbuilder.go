@@ -3,11 +3,11 @@ package router
 import (
 	"fmt"
 	"net/http"
-	"path"
 
 	"github.com/go-errors/errors"
 
 	"github.com/octavore/nagax/router/httperror"
+	nerrors "github.com/octavore/nagax/util/errors"
 )
 
 // handleErrorLogBuilder is a helper to generate a log line for HandleError
@@ -45,26 +45,29 @@ func (b *handleErrorLogBuilder) WithError(err error) *handleErrorLogBuilder {
 	return b
 }
 
-func (b *handleErrorLogBuilder) String() string {
-	msg := fmt.Sprintf("[%d] %s %s", b.status, b.path, b.action)
+// Message returns the log message, e.g. "[500] /api/test error-json"
+func (b *handleErrorLogBuilder) Message() string {
+	return fmt.Sprintf("[%d] %s %s", b.status, b.path, b.action)
+}
+
+// KV returns the key-value pairs to log with Message
+func (b *handleErrorLogBuilder) KV() []any {
+	kv := []any{}
 	if b.detail != nil {
-		msg += fmt.Sprintf(" detail:%q", *b.detail)
+		kv = append(kv, "detail", *b.detail)
 	}
 
 	loggedError := httperror.UnwrapAll(b.err)
 	var httpErr *httperror.HTTPError
 	if errors.As(b.err, &httpErr) && httpErr.BaseError == nil {
-		msg += fmt.Sprintf(" error:<nil>")
+		kv = append(kv, "error", nil)
 	} else if b.err != nil {
-		msg += fmt.Sprintf(" error:%q error-type:%T", loggedError, loggedError)
+		kv = append(kv, "error", loggedError.Error(), "error-type", fmt.Sprintf("%T", loggedError))
 	}
 
-	var errWithStack *errors.Error
-	if errors.As(b.err, &errWithStack) {
-		// get the file name and line number of file where error ocurred
-		s := errWithStack.StackFrames()[0]
-		f := path.Base(s.File)
-		msg += fmt.Sprintf(" loc:%s/%s|%d", s.Package, f, s.LineNumber)
+	// file name and line number of file where error ocurred
+	if loc := nerrors.Location(b.err); loc != "" {
+		kv = append(kv, "loc", loc)
 	}
-	return msg
+	return kv
 }
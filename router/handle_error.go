@@ -7,6 +7,7 @@ import (
 
 	"github.com/octavore/nagax/proto/router/api"
 	"github.com/octavore/nagax/router/httperror"
+	"github.com/octavore/nagax/router/middleware/requestid"
 )
 
 // HandleError is the base error handler for the router.
@@ -14,7 +15,8 @@ import (
 // 2. If the route is not an API route, m.ErrorPage is called to show an error page
 // 3. If err is a httperror.HTTPError, its ToProto function will be called for the return JSON
 // 4. Otherwise, we will return a JSON response without any detail (probably a 500 unless err implements GetCode)
-// *  If the final status code is 500, we will report the original err with m.Logger.ErrorCtx
+// *  If the final status code is 500, we will report the original err with ErrorCtx
+// *  If the request has an ID from the requestid middleware, it is logged, and returned in the JSON
 func (m *Module) HandleError(rw http.ResponseWriter, req *http.Request, err error) int {
 	statusCode, _ := httperror.CodeFromErr(err)
 	logLine := newHandlerErrorLogBuilder(req, statusCode)
@@ -23,12 +25,12 @@ func (m *Module) HandleError(rw http.ResponseWriter, req *http.Request, err erro
 
 	if errors.As(err, &httpErrCode) {
 		// 1. HTTPErrorCode: return error code only
-		m.Logger.InfoCtx(req.Context(), logLine.WithAction("error-code"))
+		m.logError(req, logLine.WithAction("error-code"))
 		rw.WriteHeader(statusCode)
 
 	} else if !m.IsAPIRoute(req) {
 		// 2. non-api routes show an error page
-		m.Logger.InfoCtx(req.Context(), logLine.WithAction("error-page").WithError(err))
+		m.logError(req, logLine.WithAction("error-page").WithError(err))
 		m.ErrorPage(rw, req, statusCode, httperror.UnwrapAll(err))
 
 	} else {
@@ -39,18 +41,27 @@ func (m *Module) HandleError(rw http.ResponseWriter, req *http.Request, err erro
 			httpErr = &httperror.HTTPError{Code: statusCode, BaseError: err}
 		}
 
-		m.Logger.InfoCtx(req.Context(), logLine.WithAction("error-json").WithDetail(httpErr.Detail).WithError(err))
-		protoErr := Proto(rw, statusCode, &api.ErrorResponse{Errors: []*api.Error{httpErr.ToProto()}})
+		m.logError(req, logLine.WithAction("error-json").WithDetail(httpErr.Detail).WithError(err))
+		apiErr := httpErr.ToProto()
+		if id := requestid.FromContext(req.Context()); id != "" {
+			apiErr.RequestId = &id
+		}
+		protoErr := Proto(rw, statusCode, &api.ErrorResponse{Errors: []*api.Error{apiErr}})
 		if protoErr != nil {
-			m.Logger.ErrorCtx(req.Context(), protoErr)
+			m.log.ErrorCtx(req.Context(), protoErr)
 		}
 	}
 
 	// log errors with errorCtx
 	if statusCode >= 500 {
 		// note: this returns the original error because it may have more context, eg the stack trace.
-		m.Logger.ErrorCtx(req.Context(), err)
+		m.log.ErrorCtx(req.Context(), err)
 	}
 
 	return statusCode
 }
+
+// logError logs the line built by HandleError with its fields
+func (m *Module) logError(req *http.Request, logLine *handleErrorLogBuilder) {
+	m.log.InfoKV(req.Context(), logLine.Message(), logLine.KV()...)
+}
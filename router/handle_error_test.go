@@ -11,6 +11,7 @@ import (
 	"github.com/shoenig/test/must"
 
 	"github.com/octavore/nagax/router/httperror"
+	"github.com/octavore/nagax/router/middleware/requestid"
 	nerrors "github.com/octavore/nagax/util/errors"
 )
 
@@ -36,7 +37,7 @@ func TestHandleError(t *testing.T) {
 		desc:         "fmt-errorf",
 		err:          fmt.Errorf("non-httperror"),
 		expectedCode: 500,
-		expectedLog:  `[500] /api/test error-json detail:"" error:"non-httperror" error-type:*errors.errorString`,
+		expectedLog:  `router: [500] /api/test error-json detail="" error=non-httperror error-type=*errors.errorString`,
 		expectedBody: `{
 			"errors": [{
 				"code": 500,
@@ -47,7 +48,7 @@ func TestHandleError(t *testing.T) {
 		desc:         "wrapped-error",
 		err:          errWithStack,
 		expectedCode: 500,
-		expectedLog:  `[500] /api/test error-json detail:"" error:"has stack" error-type:*errors.errorString loc:github.com/octavore/nagax/router/handle_error_test.go|27`,
+		expectedLog:  `router: [500] /api/test error-json detail="" error="has stack" error-type=*errors.errorString loc=github.com/octavore/nagax/router/handle_error_test.go|28`,
 		expectedBody: `{
 			"errors": [{
 				"code": 500,
@@ -58,7 +59,7 @@ func TestHandleError(t *testing.T) {
 		desc:         "error-code-only",
 		err:          httperror.HTTPErrorCode(403),
 		expectedCode: 403,
-		expectedLog:  `[403] /api/test error-code`,
+		expectedLog:  `router: [403] /api/test error-code`,
 	}, {
 		desc:         "httperror",
 		err:          httperror.NotFound("Resource not found."),
@@ -70,7 +71,7 @@ func TestHandleError(t *testing.T) {
 				"detail":"Resource not found."
 			}]
 		}`,
-		expectedLog: `[404] /api/test error-json detail:"Resource not found." error:<nil>`,
+		expectedLog: `router: [404] /api/test error-json detail="Resource not found." error=<nil>`,
 	}, {
 		desc:         "httperror-with-error",
 		err:          httperror.BadRequest("This is a bad request.").WithError(fmt.Errorf("hidden error")),
@@ -82,7 +83,7 @@ func TestHandleError(t *testing.T) {
 				"detail":"This is a bad request."
 			}]
 		}`,
-		expectedLog: `[400] /api/test error-json detail:"This is a bad request." error:"hidden error" error-type:*errors.errorString`,
+		expectedLog: `router: [400] /api/test error-json detail="This is a bad request." error="hidden error" error-type=*errors.errorString`,
 	}, {
 		desc:         "httperror-with-error-with-stack",
 		err:          httperror.InternalError().WithError(errWithStack),
@@ -93,7 +94,7 @@ func TestHandleError(t *testing.T) {
 				"title": "internal_server_error"
 			}]
 		}`,
-		expectedLog: `[500] /api/test error-json detail:"" error:"has stack" error-type:*errors.errorString loc:github.com/octavore/nagax/router/handle_error_test.go|27`,
+		expectedLog: `router: [500] /api/test error-json detail="" error="has stack" error-type=*errors.errorString loc=github.com/octavore/nagax/router/handle_error_test.go|28`,
 	}, {
 		desc:         "httperror-with-custom-error-with-stack",
 		err:          httperror.InternalError().WithDetail("Another message.").WithError(customErrWithStack),
@@ -105,7 +106,7 @@ func TestHandleError(t *testing.T) {
 				"detail":"Another message."
 			}]
 		}`,
-		expectedLog: `[500] /api/test error-json detail:"Another message." error:"custom error" error-type:*router.CustomError loc:github.com/octavore/nagax/router/handle_error_test.go|28`,
+		expectedLog: `router: [500] /api/test error-json detail="Another message." error="custom error" error-type=*router.CustomError loc=github.com/octavore/nagax/router/handle_error_test.go|29`,
 	}}
 
 	for _, tc := range testCases {
@@ -123,7 +124,7 @@ func TestHandleError(t *testing.T) {
 			}
 
 			if tc.expectedCode >= 500 {
-				test.Eq(t, env.logger.Errors, []string{tc.err.Error()})
+				test.Eq(t, env.logger.Errors, []string{"router: " + tc.err.Error()})
 			} else {
 				test.SliceEmpty(t, env.logger.Errors)
 			}
@@ -171,7 +172,7 @@ func TestHandleErrorNonAPIRoute(t *testing.T) {
 			test.Eq(t, "<a href=\"/\">Temporary Redirect</a>.\n\n", rr.Body.String())
 			test.Eq(t, 1, errorPageCalls)
 			if tc.expectedCode >= 500 {
-				test.Eq(t, env.logger.Errors, []string{tc.err.Error()})
+				test.Eq(t, env.logger.Errors, []string{"router: " + tc.err.Error()})
 			} else {
 				test.SliceEmpty(t, env.logger.Errors)
 			}
@@ -185,3 +186,104 @@ func TestHandleErrorNonAPIRoute(t *testing.T) {
 	// assert.EqualError(t, err, expectedStr)
 	// assert.ElementsMatch(t, []string{expectedStr}, env.logger.Warnings)
 }
+
+func TestHandleErrorLogFields(t *testing.T) {
+	env := setup()
+	defer env.stop()
+
+	env.module.GET("/api/items/:id", func(rw http.ResponseWriter, req *http.Request, par Params) error {
+		return httperror.NotFound("not found")
+	})
+	req := httptest.NewRequest("GET", "/api/items/1", nil)
+	rr := httptest.NewRecorder()
+	env.logger.Reset()
+	env.module.HTTPRouter.ServeHTTP(rr, req)
+
+	test.Eq(t, http.StatusNotFound, rr.Code)
+	must.SliceLen(t, 1, env.logger.Infos)
+	test.StrHasPrefix(t, `router: [404] /api/items/1 error-json method=GET route=/api/items/:id detail="not found" error=<nil>`, env.logger.Infos[0])
+}
+
+func TestHandleErrorRequestID(t *testing.T) {
+	env := setup()
+	defer env.stop()
+
+	env.module.Middleware.Prepend(requestid.Default)
+	env.module.GET("/api/items/:id", func(rw http.ResponseWriter, req *http.Request, par Params) error {
+		return httperror.NotFound("not found")
+	})
+	req := httptest.NewRequest("GET", "/api/items/1", nil)
+	req.Header.Set(requestid.Header, "req-1")
+	rr := httptest.NewRecorder()
+	env.logger.Reset()
+	env.module.Middleware.ServeHTTP(rr, req)
+
+	test.Eq(t, http.StatusNotFound, rr.Code)
+	test.Eq(t, "req-1", rr.Header().Get(requestid.Header))
+	test.EqJSON(t, `{
+		"errors": [{
+			"code": 404,
+			"title": "not_found",
+			"detail": "not found",
+			"requestId": "req-1"
+		}]
+	}`, rr.Body.String())
+	must.SliceLen(t, 1, env.logger.Infos)
+	test.StrHasPrefix(t, `router: [404] /api/items/1 error-json request_id=req-1 method=GET route=/api/items/:id detail="not found"`, env.logger.Infos[0])
+}
+
+func TestHandlePanic(t *testing.T) {
+	env := setup()
+	defer env.stop()
+
+	env.module.GET("/api/panic", func(rw http.ResponseWriter, req *http.Request, par Params) error {
+		panic("boom")
+	})
+	env.module.GET("/panic", func(rw http.ResponseWriter, req *http.Request, par Params) error {
+		var m map[string]int
+		m["a"] = 1 // runtime error
+		return nil
+	})
+
+	t.Run("api", func(t *testing.T) {
+		var reported error
+		env.module.ErrorHandler = func(rw http.ResponseWriter, req *http.Request, err error) {
+			reported = err
+			env.module.HandleError(rw, req, err)
+		}
+		req := httptest.NewRequest("GET", "/api/panic", nil)
+		rr := httptest.NewRecorder()
+		env.logger.Reset()
+		env.module.HTTPRouter.ServeHTTP(rr, req)
+
+		test.Eq(t, http.StatusInternalServerError, rr.Code)
+		test.EqJSON(t, `{"errors": [{"code": 500, "title": "internal_server_error"}]}`, rr.Body.String())
+		test.Eq(t, []string{"router: panic: boom method=GET route=/api/panic"}, env.logger.Errors)
+
+		var errWithStack *errors.Error
+		must.ErrorAs(t, reported, &errWithStack)
+		var panicErr *PanicError
+		must.ErrorAs(t, reported, &panicErr)
+		test.Eq(t, "boom", panicErr.Value)
+		test.Eq(t, "/api/panic", panicErr.GetRequest().URL.Path)
+		test.StrHasPrefix(t, "github.com/octavore/nagax/router/handle_error_test.go|", nerrors.Location(reported))
+	})
+
+	t.Run("error-page", func(t *testing.T) {
+		env.module.ErrorHandler = func(rw http.ResponseWriter, req *http.Request, err error) {
+			env.module.HandleError(rw, req, err)
+		}
+		status := 0
+		env.module.ErrorPage = func(rw http.ResponseWriter, req *http.Request, s int, err error) {
+			status = s
+			rw.WriteHeader(s)
+		}
+		rr := httptest.NewRecorder()
+		env.logger.Reset()
+		env.module.HTTPRouter.ServeHTTP(rr, httptest.NewRequest("GET", "/panic", nil))
+
+		test.Eq(t, http.StatusInternalServerError, status)
+		test.Eq(t, http.StatusInternalServerError, rr.Code)
+		test.Eq(t, []string{"router: panic: assignment to entry in nil map method=GET route=/panic"}, env.logger.Errors)
+	})
+}
@@ -0,0 +1,280 @@
+package accesslog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/octavore/nagax/router/middleware/requestid"
+)
+
+// Formats of the access log
+const (
+	FormatCommon   = "common"   // Common Log Format
+	FormatCombined = "combined" // Combined Log Format, with referer and user agent, the default
+	FormatJSON     = "json"     // one JSON object per line
+)
+
+type option func(l *accessLog)
+
+// WithFormat sets the format to FormatCommon, FormatCombined or FormatJSON
+func WithFormat(format string) option {
+	return func(l *accessLog) {
+		l.format = format
+	}
+}
+
+// Exclude requests whose path matches one of the httprouter patterns, e.g.
+// "/healthz" or "/static/*filepath"
+func Exclude(patterns ...string) option {
+	return func(l *accessLog) {
+		noopHandler := func(http.ResponseWriter, *http.Request, httprouter.Params) {}
+		for _, pattern := range patterns {
+			l.exclude.GET(pattern, noopHandler)
+		}
+	}
+}
+
+// TrustProxy logs the client address from the X-Forwarded-For or X-Real-IP
+// headers, where hops is the number of proxies in front of the server. Each
+// proxy appends the address it received the request from to X-Forwarded-For,
+// so the client is the hops-th entry from the right. Entries further left are
+// set by the client and can be forged. Only use this behind proxies which set
+// these headers.
+func TrustProxy(hops int) option {
+	if hops < 1 {
+		panic(fmt.Sprintf("accesslog: invalid number of proxy hops %d", hops))
+	}
+	return func(l *accessLog) {
+		l.proxyHops = hops
+	}
+}
+
+type accessLog struct {
+	format    string
+	exclude   *httprouter.Router
+	proxyHops int // 0 if proxy headers are not trusted
+	now       func() time.Time
+
+	mu sync.Mutex // protects w
+	w  io.Writer
+}
+
+// New returns a middleware which writes a line to w for each request, with
+// its status, size, route and user. Handlers wrapped by the router set the
+// route with SetRoute, and the users module sets the user with SetUserID.
+func New(w io.Writer, opts ...option) func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	l := &accessLog{
+		format:  FormatCombined,
+		exclude: httprouter.New(),
+		now:     time.Now,
+		w:       w,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.format != FormatCommon && l.format != FormatCombined && l.format != FormatJSON {
+		panic(fmt.Sprintf("accesslog: invalid format %q", l.format))
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		if h, _, _ := l.exclude.Lookup("GET", req.URL.Path); h != nil {
+			next(rw, req)
+			return
+		}
+		start := l.now()
+		e := &entry{}
+		lw := &responseWriter{ResponseWriter: rw, status: http.StatusOK}
+		next(lw, req.WithContext(context.WithValue(req.Context(), entryKey{}, e)))
+		l.write(req, lw, e, start)
+	}
+}
+
+// entry holds the fields set by handlers further down the chain
+type entry struct {
+	route  string
+	userID string
+}
+
+type entryKey struct{}
+
+// SetRoute sets the route pattern of the request, e.g. "/api/items/:id"
+func SetRoute(ctx context.Context, route string) {
+	if e, ok := ctx.Value(entryKey{}).(*entry); ok {
+		e.route = route
+	}
+}
+
+// SetUserID sets the ID of the authenticated user of the request
+func SetUserID(ctx context.Context, userID string) {
+	if e, ok := ctx.Value(entryKey{}).(*entry); ok {
+		e.userID = userID
+	}
+}
+
+func (l *accessLog) write(req *http.Request, lw *responseWriter, e *entry, start time.Time) {
+	var line []byte
+	if l.format == FormatJSON {
+		line = l.formatJSON(req, lw, e, start)
+	} else {
+		line = []byte(l.formatCLF(req, lw, e, start))
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.w.Write(line)
+}
+
+// formatCLF formats the request in the Common or Combined Log Format, e.g.
+// `127.0.0.1 - 1 [10/Oct/2000:13:55:36 -0700] "GET /a HTTP/1.1" 200 2326 "-" "curl/8.0"`
+func (l *accessLog) formatCLF(req *http.Request, lw *responseWriter, e *entry, start time.Time) string {
+	size := "-"
+	if lw.size > 0 {
+		size = fmt.Sprint(lw.size)
+	}
+	line := fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s",
+		l.remoteAddr(req),
+		orDash(escape(e.userID)),
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		req.Method,
+		escape(req.URL.RequestURI()),
+		req.Proto,
+		lw.status,
+		size,
+	)
+	if l.format == FormatCombined {
+		line += fmt.Sprintf(" \"%s\" \"%s\"", orDash(escape(req.Referer())), orDash(escape(req.UserAgent())))
+	}
+	return line + "\n"
+}
+
+func (l *accessLog) formatJSON(req *http.Request, lw *responseWriter, e *entry, start time.Time) []byte {
+	b, _ := json.Marshal(struct {
+		Time       string  `json:"time"`
+		RemoteAddr string  `json:"remote_addr"`
+		Method     string  `json:"method"`
+		Path       string  `json:"path"`
+		Route      string  `json:"route,omitempty"`
+		Proto      string  `json:"proto"`
+		Status     int     `json:"status"`
+		Bytes      int64   `json:"bytes"`
+		DurationMS float64 `json:"duration_ms"`
+		UserID     string  `json:"user_id,omitempty"`
+		RequestID  string  `json:"request_id,omitempty"`
+		Referer    string  `json:"referer,omitempty"`
+		UserAgent  string  `json:"user_agent,omitempty"`
+	}{
+		Time:       start.Format(time.RFC3339Nano),
+		RemoteAddr: l.remoteAddr(req),
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Route:      e.route,
+		Proto:      req.Proto,
+		Status:     lw.status,
+		Bytes:      lw.size,
+		DurationMS: float64(l.now().Sub(start).Microseconds()) / 1000,
+		UserID:     e.userID,
+		RequestID:  requestid.FromContext(req.Context()),
+		Referer:    req.Referer(),
+		UserAgent:  req.UserAgent(),
+	})
+	return append(b, '\n')
+}
+
+// remoteAddr returns the client IP of the request, from the proxy headers
+// if TrustProxy is set
+func (l *accessLog) remoteAddr(req *http.Request) string {
+	if l.proxyHops > 0 {
+		if fwd := forwardedFor(req); len(fwd) > 0 {
+			return escape(fwd[max(len(fwd)-l.proxyHops, 0)])
+		}
+		if ip := req.Header.Get("X-Real-IP"); ip != "" {
+			return escape(ip)
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// forwardedFor returns the addresses in the X-Forwarded-For headers of req,
+// which may be repeated
+func forwardedFor(req *http.Request) []string {
+	addrs := []string{}
+	for _, h := range req.Header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(h, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	return addrs
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// escape quotes, backslashes and control characters, which could otherwise
+// be used to forge log lines
+func escape(s string) string {
+	q := fmt.Sprintf("%q", s)
+	return q[1 : len(q)-1]
+}
+
+// responseWriter records the status and size of the response
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer does
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, e.g. for websockets
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("accesslog: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
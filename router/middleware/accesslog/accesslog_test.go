@@ -0,0 +1,111 @@
+package accesslog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func serve(mw func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc), req *http.Request) {
+	mw(httptest.NewRecorder(), req, func(rw http.ResponseWriter, req *http.Request) {
+		SetRoute(req.Context(), "/items/:id")
+		SetUserID(req.Context(), "u1")
+		rw.WriteHeader(http.StatusCreated)
+		_, _ = rw.Write([]byte("hello"))
+	})
+}
+
+func fixedClock(l *accessLog) {
+	t := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+	l.now = func() time.Time { return t }
+}
+
+func newRequest() *http.Request {
+	req := httptest.NewRequest("GET", "/items/1?q=a", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("User-Agent", `curl/8.0 "x"`)
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.2")
+	return req
+}
+
+func TestFormats(t *testing.T) {
+	testCases := []struct {
+		format   string
+		opts     []option
+		expected string
+	}{{
+		format:   FormatCommon,
+		expected: `10.0.0.1 - u1 [10/Oct/2000:13:55:36 -0700] "GET /items/1?q=a HTTP/1.1" 201 5` + "\n",
+	}, {
+		format:   FormatCombined,
+		opts:     []option{TrustProxy(2)},
+		expected: `1.2.3.4 - u1 [10/Oct/2000:13:55:36 -0700] "GET /items/1?q=a HTTP/1.1" 201 5 "-" "curl/8.0 \"x\""` + "\n",
+	}, {
+		format: FormatJSON,
+		expected: `{"time":"2000-10-10T13:55:36-07:00","remote_addr":"10.0.0.1","method":"GET","path":"/items/1",` +
+			`"route":"/items/:id","proto":"HTTP/1.1","status":201,"bytes":5,"duration_ms":0,"user_id":"u1","user_agent":"curl/8.0 \"x\""}` + "\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]option{WithFormat(tc.format), fixedClock}, tc.opts...)
+			serve(New(buf, opts...), newRequest())
+			test.Eq(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestExclude(t *testing.T) {
+	buf := &bytes.Buffer{}
+	mw := New(buf, Exclude("/healthz", "/static/*filepath"))
+	serve(mw, httptest.NewRequest("GET", "/healthz", nil))
+	serve(mw, httptest.NewRequest("GET", "/static/app.js", nil))
+	test.Eq(t, "", buf.String())
+
+	serve(mw, httptest.NewRequest("GET", "/healthz/other", nil))
+	must.StrContains(t, buf.String(), `"GET /healthz/other HTTP/1.1" 201 5`)
+}
+
+func TestDefaultStatus(t *testing.T) {
+	buf := &bytes.Buffer{}
+	New(buf, WithFormat(FormatCommon))(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil),
+		func(rw http.ResponseWriter, req *http.Request) {})
+	test.StrContains(t, buf.String(), `"GET / HTTP/1.1" 200 -`)
+}
+
+func TestEscapeUserID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	New(buf, WithFormat(FormatCommon), fixedClock)(httptest.NewRecorder(), newRequest(),
+		func(rw http.ResponseWriter, req *http.Request) {
+			SetUserID(req.Context(), "u1 \"x\"\n10.0.0.9")
+		})
+	test.Eq(t, `10.0.0.1 - u1 \"x\"\n10.0.0.9 [10/Oct/2000:13:55:36 -0700] "GET /items/1?q=a HTTP/1.1" 200 -`+"\n", buf.String())
+}
+
+func TestTrustProxy(t *testing.T) {
+	testCases := []struct {
+		hops     int
+		fwd      []string
+		expected string
+	}{
+		{hops: 1, fwd: []string{"6.6.6.6, 1.2.3.4"}, expected: "1.2.3.4"},
+		{hops: 2, fwd: []string{"6.6.6.6, 1.2.3.4, 10.0.0.2"}, expected: "1.2.3.4"},
+		{hops: 2, fwd: []string{"6.6.6.6, 1.2.3.4", "10.0.0.2"}, expected: "1.2.3.4"},
+		{hops: 3, fwd: []string{"1.2.3.4, 10.0.0.2"}, expected: "1.2.3.4"},
+		{hops: 1, expected: "10.0.0.1"},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		for _, fwd := range tc.fwd {
+			req.Header.Add("X-Forwarded-For", fwd)
+		}
+		l := &accessLog{proxyHops: tc.hops}
+		test.Eq(t, tc.expected, l.remoteAddr(req))
+	}
+}
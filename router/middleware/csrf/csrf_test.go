@@ -3,6 +3,7 @@ package csrf
 import (
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"github.com/octavore/naga/service"
@@ -14,11 +15,15 @@ import (
 
 type TestModule struct {
 	*Module
+	keyDir string
 }
 
 func (m *TestModule) Init(c *service.Config) {
 	c.Setup = func() error {
 		m.Logger.Logger = &memlogger.MemoryLogger{}
+		// generate the session key in a temp dir instead of the working dir
+		m.Session.KeyFile = filepath.Join(m.keyDir, "session.key")
+		m.CSRF.KeyFile = filepath.Join(m.keyDir, "session.key")
 		return nil
 	}
 }
@@ -29,8 +34,8 @@ type testEnv struct {
 	stop   func()
 }
 
-func setup() testEnv {
-	module, stop := service.New(&TestModule{}).StartForTest()
+func setup(t *testing.T) testEnv {
+	module, stop := service.New(&TestModule{keyDir: t.TempDir()}).StartForTest()
 	return testEnv{
 		module: module.Module,
 		logger: module.Logger.Logger.(*memlogger.MemoryLogger),
@@ -39,7 +44,7 @@ func setup() testEnv {
 }
 
 func TestNew(t *testing.T) {
-	env := setup()
+	env := setup(t)
 	defer env.stop()
 
 	testHandler := env.module.New("/ignore", "/ignore2/:id")
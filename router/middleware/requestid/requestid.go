@@ -0,0 +1,61 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/octavore/nagax/logger"
+	"github.com/octavore/nagax/util/token"
+)
+
+// Header is the request and response header with the request ID
+const Header = "X-Request-ID"
+
+// maxLength of request IDs accepted from clients or proxies
+const maxLength = 128
+
+type requestIDKey struct{}
+
+// Default is the request ID middleware
+var Default = New()
+
+// New returns a middleware which reads the request ID from the X-Request-ID
+// header, or generates one if it is missing or invalid. The ID is stored in
+// the request context, added to its log fields as request_id, and echoed in
+// the response header.
+func New() func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		id := req.Header.Get(Header)
+		if !valid(id) {
+			id = token.New64()
+		}
+		rw.Header().Set(Header, id)
+		next(rw, req.WithContext(WithRequestID(req.Context(), id)))
+	}
+}
+
+// WithRequestID stores id in ctx and adds it to the log fields
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return logger.WithFields(ctx, "request_id", id)
+}
+
+// FromContext returns the request ID in ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// valid returns true if id is not empty, and only has printable ASCII
+// characters other than spaces and quotes, so it is safe to log
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range []byte(id) {
+		if c <= ' ' || c > '~' || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shoenig/test"
+
+	"github.com/octavore/nagax/logger"
+)
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		header   string
+		expected string // empty if a new ID should be generated
+	}{
+		{desc: "missing"},
+		{desc: "from-header", header: "abc-123", expected: "abc-123"},
+		{desc: "has-space", header: "abc 123"},
+		{desc: "has-quote", header: `abc"123`},
+		{desc: "too-long", header: strings.Repeat("a", maxLength+1)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tc.header != "" {
+				req.Header.Set(Header, tc.header)
+			}
+			rr := httptest.NewRecorder()
+			var id string
+			var fields []any
+			Default(rr, req, func(rw http.ResponseWriter, req *http.Request) {
+				id = FromContext(req.Context())
+				fields = logger.Fields(req.Context())
+			})
+
+			if tc.expected != "" {
+				test.Eq(t, tc.expected, id)
+			} else {
+				test.Eq(t, 13, len(id))
+				test.NotEq(t, tc.header, id)
+			}
+			test.Eq(t, id, rr.Header().Get(Header))
+			test.Eq(t, []any{"request_id", id}, fields)
+		})
+	}
+}
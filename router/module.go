@@ -2,7 +2,9 @@ package router
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/octavore/nagax/config"
 	"github.com/octavore/nagax/logger"
 	"github.com/octavore/nagax/router/middleware"
+	"github.com/octavore/nagax/router/middleware/accesslog"
 	"github.com/octavore/nagax/util/errors"
 )
 
@@ -24,8 +27,11 @@ type (
 
 // Config for the router module
 type Config struct {
-	Port         int  `json:"port"`
-	BindExternal bool `json:"bindext"`
+	Port         int  `json:"port" default:"8000" validate:"min=0,max=65535" desc:"port to listen on, 0 picks a free port"`
+	BindExternal bool `json:"bindext" desc:"listen on 0.0.0.0 instead of 127.0.0.1"`
+
+	Server ServerConfig `json:"server"`
+	TLS    TLSConfig    `json:"tls"`
 }
 
 // Module router implements basic routing with helpers for protobuf-rootd responses.
@@ -43,13 +49,20 @@ type Module struct {
 
 	APIPrefixes []string // paths with this prefix get API errors
 
-	config Config
-	server *http.Server
+	log       logger.Logger // named "router"
+	config    Config
+	server    *http.Server
+	listener  net.Listener
+	redirect  *http.Server // redirects http to https, see TLSConfig
+	tlsConfig *tls.Config  // nil unless https is enabled
+	cert      *certificate
+	stop      chan struct{}
 }
 
 // Init implements service.Init
 func (m *Module) Init(c *service.Config) {
 	c.Setup = func() error {
+		m.log = m.Logger.Named("router")
 		m.HTTPRouter = httprouter.New()
 		m.APIPrefixes = []string{"/"} // for backward compatibility
 		m.IsAPIRoute = m.isAPIRoute
@@ -64,15 +77,22 @@ func (m *Module) Init(c *service.Config) {
 		m.Root = http.NewServeMux()
 		m.Root.Handle("/", m.HTTPRouter)
 		m.Middleware = middleware.NewServer(m.Root.ServeHTTP)
-		m.Config.ReadConfig(&m.config)
-		return nil
+		err := m.Config.ReadConfig(&m.config)
+		if err != nil {
+			return err
+		}
+		return m.setupTLS(c.Env())
 	}
 
 	c.Start = func() {
-		laddr := m.laddr()
-		m.Logger.Infof("router: listening on %s", laddr)
-		m.server = &http.Server{Addr: laddr, Handler: m.Middleware}
-		go m.server.ListenAndServe()
+		if m.tlsConfig != nil {
+			m.stop = make(chan struct{})
+			go m.reloadCertOnSIGHUP(m.stop)
+		}
+		err := m.listen()
+		if err != nil {
+			c.Fatal(err)
+		}
 	}
 
 	c.Stop = func() {
@@ -82,21 +102,37 @@ func (m *Module) Init(c *service.Config) {
 
 // Shutdown the server
 func (m *Module) Shutdown(ctx context.Context) {
-	m.Logger.Infof("shutting down %s...", m.server.Addr)
+	m.log.Infof("shutting down %s...", m.server.Addr)
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	if m.redirect != nil {
+		err := m.redirect.Shutdown(ctx)
+		if err != nil {
+			m.log.Error(errors.Wrap(err))
+		}
+	}
 	err := m.server.Shutdown(ctx)
 	if err != nil {
-		m.Logger.Error(errors.Wrap(err))
+		m.log.Error(errors.Wrap(err))
+	}
+}
+
+// Addr returns the address the server listens on. If the port is 0, this is
+// the address with the port picked by the OS once the module has started.
+func (m *Module) Addr() string {
+	if m.listener != nil {
+		return m.listener.Addr().String()
 	}
+	return m.laddr()
 }
 
 func (m *Module) laddr() string {
 	iface := "127.0.0.1"
-	port := 8000
-	if m.config.Port != 0 {
-		port = m.config.Port
-		if port == 80 || port == 443 {
-			iface = "0.0.0.0"
-		}
+	port := m.config.Port
+	if port == 80 || port == 443 {
+		iface = "0.0.0.0"
 	}
 	if m.config.BindExternal {
 		iface = "0.0.0.0"
@@ -106,39 +142,40 @@ func (m *Module) laddr() string {
 
 // POST is a shortcut for m.HTTPRouter.POST
 func (m *Module) POST(path string, h Handle) {
-	m.HTTPRouter.POST(path, m.wrap(h))
+	m.HTTPRouter.POST(path, m.wrap("POST", path, h))
 }
 
 // GET is a shortcut for m.HTTPRouter.GET
 func (m *Module) GET(path string, h Handle) {
-	m.HTTPRouter.GET(path, m.wrap(h))
+	m.HTTPRouter.GET(path, m.wrap("GET", path, h))
 }
 
 // PUT is a shortcut for m.HTTPRouter.PUT
 func (m *Module) PUT(path string, h Handle) {
-	m.HTTPRouter.PUT(path, m.wrap(h))
+	m.HTTPRouter.PUT(path, m.wrap("PUT", path, h))
 }
 
 // PATCH is a shortcut for m.HTTPRouter.PATCH
 func (m *Module) PATCH(path string, h Handle) {
-	m.HTTPRouter.PATCH(path, m.wrap(h))
+	m.HTTPRouter.PATCH(path, m.wrap("PATCH", path, h))
 }
 
 // DELETE is a shortcut for m.HTTPRouter.DELETE
 func (m *Module) DELETE(path string, h Handle) {
-	m.HTTPRouter.DELETE(path, m.wrap(h))
+	m.HTTPRouter.DELETE(path, m.wrap("DELETE", path, h))
 }
 
 // Handle is a shortcut for m.HTTPRouter.Handle
 func (m *Module) Handle(method, path string, h http.HandlerFunc) {
 	m.HTTPRouter.Handle(method, path, func(rw http.ResponseWriter, req *http.Request, _ Params) {
+		accesslog.SetRoute(req.Context(), path)
 		h(rw, req)
 	})
 }
 
 // WrappedHandle is a shortcut for m.HTTPRouter.Handle
 func (m *Module) WrappedHandle(method, path string, h Handle) {
-	m.HTTPRouter.Handle(method, path, m.wrap(h))
+	m.HTTPRouter.Handle(method, path, m.wrap(method, path, h))
 }
 
 // Subrouter creates a new router rooted at path
@@ -148,9 +185,13 @@ func (m *Module) Subrouter(path string) *httprouter.Router {
 	return r
 }
 
-// wrap the given handler to handle errors
-func (m *Module) wrap(h Handle) httprouter.Handle {
+// wrap the given handler to handle errors and panics, and add the method and
+// route to the log fields of the request context and the access log
+func (m *Module) wrap(method, path string, h Handle) httprouter.Handle {
 	return func(rw http.ResponseWriter, req *http.Request, par Params) {
+		accesslog.SetRoute(req.Context(), path)
+		req = req.WithContext(logger.WithFields(req.Context(), "method", method, "route", path))
+		defer m.recoverPanic(rw, req)
 		err := h(rw, req, par)
 		if err != nil && m.ErrorHandler != nil {
 			m.ErrorHandler(rw, req, errors.Wrap(err))
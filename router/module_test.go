@@ -1,8 +1,21 @@
 package router
 
 import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
 	"github.com/octavore/naga/service"
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
 
+	"github.com/octavore/nagax/config"
+	"github.com/octavore/nagax/router/middleware/accesslog"
 	"github.com/octavore/nagax/util/memlogger"
 )
 
@@ -24,7 +37,18 @@ type testEnv struct {
 }
 
 func setup() testEnv {
-	module, stop := service.New(&TestModule{}).StartForTest()
+	return setupWithConfig(nil)
+}
+
+// setupWithConfig starts the module with the config overrides, see config.WithOverride
+func setupWithConfig(overrides map[string]any) testEnv {
+	tm := &TestModule{}
+	svc := service.New(tm)
+	tm.Config.Configure(config.WithOverride("port", 0)) // pick a free port
+	for path, value := range overrides {
+		tm.Config.Configure(config.WithOverride(path, value))
+	}
+	module, stop := svc.StartForTest()
 	module.APIPrefixes = []string{"/api/"}
 	return testEnv{
 		module: module.Module,
@@ -32,3 +56,61 @@ func setup() testEnv {
 		stop:   stop,
 	}
 }
+
+func TestModuleFreePort(t *testing.T) {
+	env1 := setup()
+	defer env1.stop()
+	env2 := setup()
+	defer env2.stop()
+
+	test.NotEq(t, "127.0.0.1:8000", env1.module.Addr())
+	test.NotEq(t, env1.module.Addr(), env2.module.Addr())
+
+	// the server is listening once the module has started
+	resp, err := http.Get("http://" + env1.module.Addr() + "/")
+	must.NoError(t, err)
+	resp.Body.Close()
+	test.Eq(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestModuleListenError(t *testing.T) {
+	env := setup()
+	defer env.stop()
+
+	_, port, err := net.SplitHostPort(env.module.Addr())
+	must.NoError(t, err)
+	m := &Module{log: env.module.log}
+	m.config.Port, _ = strconv.Atoi(port)
+	err = m.listen()
+	must.Error(t, err)
+	test.True(t, strings.Contains(err.Error(), "address already in use"))
+}
+
+func TestModuleServerConfig(t *testing.T) {
+	env := setupWithConfig(map[string]any{
+		"server.write_timeout":       5 * time.Second,
+		"server.read_header_timeout": "5s",
+	})
+	defer env.stop()
+
+	s := env.module.server
+	test.Eq(t, 5*time.Second, s.ReadHeaderTimeout)
+	test.Eq(t, 60*time.Second, s.ReadTimeout)
+	test.Eq(t, 5*time.Second, s.WriteTimeout)
+	test.Eq(t, 120*time.Second, s.IdleTimeout)
+	test.Eq(t, 1<<20, s.MaxHeaderBytes)
+}
+
+func TestAccessLogRoute(t *testing.T) {
+	env := setup()
+	defer env.stop()
+
+	buf := &bytes.Buffer{}
+	env.module.Middleware.Prepend(accesslog.New(buf, accesslog.WithFormat(accesslog.FormatJSON)))
+	env.module.GET("/api/items/:id", func(rw http.ResponseWriter, req *http.Request, par Params) error {
+		return JSON(rw, http.StatusOK, map[string]string{"id": par.ByName("id")})
+	})
+	env.module.Middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/items/1", nil))
+
+	test.StrContains(t, buf.String(), `"path":"/api/items/1","route":"/api/items/:id","proto":"HTTP/1.1","status":200,"bytes":15`)
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	goerrors "github.com/go-errors/errors"
+)
+
+// PanicError is a panic recovered from a handler. It implements GetRequest
+// so that bugsnag reports include the request.
+type PanicError struct {
+	Value   any // the value passed to panic
+	Request *http.Request
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// GetRequest returns the request which caused the panic
+func (e *PanicError) GetRequest() *http.Request {
+	return e.Request
+}
+
+// recoverPanic must be deferred. It recovers from panics in handlers, and
+// sends them to the ErrorHandler as a *errors.Error with the stack of the panic.
+func (m *Module) recoverPanic(rw http.ResponseWriter, req *http.Request) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if v == http.ErrAbortHandler {
+		panic(v) // net/http aborts the response without logging
+	}
+	// skip recoverPanic and runtime.gopanic, so the stack starts where panic was called
+	err := goerrors.Wrap(&PanicError{Value: v, Request: req}, 2)
+	if m.ErrorHandler == nil {
+		m.log.ErrorCtx(req.Context(), err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	m.ErrorHandler(rw, req, err)
+}
@@ -0,0 +1,74 @@
+package router
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/octavore/nagax/util/errors"
+)
+
+// ServerConfig for the http.Server of the router module. The defaults limit
+// slow clients, e.g. slowloris attacks.
+type ServerConfig struct {
+	ReadHeaderTimeout time.Duration `json:"read_header_timeout" default:"10s" validate:"min=0" desc:"time to read the request headers, 0 for no limit"`
+	ReadTimeout       time.Duration `json:"read_timeout" default:"60s" validate:"min=0" desc:"time to read the whole request, 0 for no limit"`
+	WriteTimeout      time.Duration `json:"write_timeout" default:"60s" validate:"min=0" desc:"time to write the response, 0 for no limit"`
+	IdleTimeout       time.Duration `json:"idle_timeout" default:"120s" validate:"min=0" desc:"time to wait for the next request on a keep-alive connection"`
+	MaxHeaderBytes    int           `json:"max_header_bytes" default:"1048576" validate:"min=0" desc:"maximum size of the request headers"`
+}
+
+// newServer returns a server for handler with the timeouts and limits from config
+func (m *Module) newServer(addr string, handler http.Handler) *http.Server {
+	c := m.config.Server
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		ReadTimeout:       c.ReadTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}
+}
+
+// listen binds the listeners, so that errors such as port conflicts are
+// returned, and serves in the background. If the port is 0, the config is
+// updated with the port picked by the OS.
+func (m *Module) listen() error {
+	l, err := net.Listen("tcp", m.laddr())
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	m.listener = l
+	m.config.Port = l.Addr().(*net.TCPAddr).Port
+	m.server = m.newServer(l.Addr().String(), m.Middleware)
+	if m.tlsConfig == nil {
+		m.log.Infof("listening on %s", l.Addr())
+		go m.serve(func() error { return m.server.Serve(l) })
+		return nil
+	}
+
+	m.server.TLSConfig = m.tlsConfig
+	m.log.Infof("listening on https://%s", l.Addr())
+	go m.serve(func() error { return m.server.ServeTLS(l, "", "") })
+	if m.config.TLS.RedirectPort == 0 {
+		return nil
+	}
+	rl, err := net.Listen("tcp", m.redirectAddr())
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	m.redirect = m.newServer(rl.Addr().String(), http.HandlerFunc(m.redirectToHTTPS))
+	m.log.Infof("redirecting http://%s to https", rl.Addr())
+	go m.serve(func() error { return m.redirect.Serve(rl) })
+	return nil
+}
+
+// serve runs fn and logs its error, unless the server was shut down
+func (m *Module) serve(fn func() error) {
+	err := fn()
+	if err != nil && err != http.ErrServerClosed {
+		m.log.Error(errors.Wrap(err))
+	}
+}
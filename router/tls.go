@@ -0,0 +1,176 @@
+package router
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/octavore/naga/service"
+
+	"github.com/octavore/nagax/keystore"
+	"github.com/octavore/nagax/util/errors"
+)
+
+// TLSConfig for the router module. https is enabled if CertFile and KeyFile
+// are set, or if SelfSigned is set outside hosted environments.
+type TLSConfig struct {
+	CertFile     string `json:"cert_file" desc:"path to the TLS certificate, enables https"`
+	KeyFile      string `json:"key_file" desc:"path to the TLS private key"`
+	MinVersion   string `json:"min_version" default:"1.2" validate:"oneof=1.0 1.1 1.2 1.3" desc:"minimum TLS version"`
+	RedirectPort int    `json:"redirect_port" validate:"min=0,max=65535" desc:"port which redirects plain http to https, 0 to disable"`
+	SelfSigned   bool   `json:"self_signed" desc:"use a self-signed localhost certificate from the keystore, in development only"`
+	KeystoreDir  string `json:"keystore_dir" desc:"directory for the self-signed certificate, defaults to nagax in the user config dir"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// localhost certificate files in the keystore, see TLSConfig.SelfSigned
+const (
+	localhostCertFile = "localhost.crt"
+	localhostKeyFile  = "localhost.key"
+)
+
+// certificate holds the loaded certificate, which can be reloaded while the
+// server is running
+type certificate struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func (c *certificate) load() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	return nil
+}
+
+// get implements tls.Config.GetCertificate
+func (c *certificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// setupTLS loads the certificate and creates the TLS config, if enabled
+func (m *Module) setupTLS(env service.Environment) error {
+	certFile, keyFile := m.config.TLS.CertFile, m.config.TLS.KeyFile
+	if m.config.TLS.SelfSigned && certFile == "" && keyFile == "" {
+		if env.IsHosted() {
+			return errors.New("router: tls.self_signed is only allowed in development")
+		}
+		dir, err := m.keystoreDir()
+		if err != nil {
+			return err
+		}
+		keyStore := &keystore.KeyStore{Dir: dir}
+		certFile, keyFile, err = keyStore.LoadLocalhostCert(localhostCertFile, localhostKeyFile)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	if certFile == "" && keyFile == "" {
+		return nil
+	}
+	if certFile == "" || keyFile == "" {
+		return errors.New("router: tls.cert_file and tls.key_file must both be set")
+	}
+	minVersion, ok := tlsVersions[m.config.TLS.MinVersion]
+	if !ok {
+		minVersion = tls.VersionTLS12
+	}
+
+	m.cert = &certificate{certFile: certFile, keyFile: keyFile}
+	err := m.cert.load()
+	if err != nil {
+		return err
+	}
+	m.tlsConfig = &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: m.cert.get,
+		NextProtos:     []string{"h2", "http/1.1"}, // enables HTTP/2
+	}
+	return nil
+}
+
+// keystoreDir returns the directory for the self-signed certificate, which is
+// outside the working directory by default so it is not checked in by mistake
+func (m *Module) keystoreDir() (string, error) {
+	if m.config.TLS.KeystoreDir != "" {
+		return m.config.TLS.KeystoreDir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	return filepath.Join(dir, "nagax"), nil
+}
+
+// ReloadCertificate reloads the certificate and key files. The previous
+// certificate is kept if they cannot be loaded.
+func (m *Module) ReloadCertificate() error {
+	if m.cert == nil {
+		return nil
+	}
+	return m.cert.load()
+}
+
+// reloadCertOnSIGHUP reloads the certificate on SIGHUP until stop is closed
+func (m *Module) reloadCertOnSIGHUP(stop <-chan struct{}) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-sighup:
+			err := m.ReloadCertificate()
+			if err != nil {
+				m.log.Errorf("error reloading certificate: %v", err)
+				continue
+			}
+			m.log.Infof("reloaded certificate %s", m.cert.certFile)
+		}
+	}
+}
+
+// redirectToHTTPS redirects plain http requests to the https port
+func (m *Module) redirectToHTTPS(rw http.ResponseWriter, req *http.Request) {
+	host := req.Host
+	if h, _, err := net.SplitHostPort(req.Host); err == nil {
+		host = h
+	}
+	if m.config.Port != 443 {
+		host = net.JoinHostPort(host, strconv.Itoa(m.config.Port))
+	}
+	status := http.StatusPermanentRedirect // keeps the method and body
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		status = http.StatusMovedPermanently
+	}
+	http.Redirect(rw, req, "https://"+host+req.URL.RequestURI(), status)
+}
+
+// redirectAddr returns the address of the http to https redirect listener,
+// on the same interface as the https listener
+func (m *Module) redirectAddr() string {
+	host, _, _ := net.SplitHostPort(m.laddr())
+	return net.JoinHostPort(host, strconv.Itoa(m.config.TLS.RedirectPort))
+}
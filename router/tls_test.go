@@ -0,0 +1,129 @@
+package router
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/octavore/naga/service"
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/octavore/nagax/keystore"
+)
+
+func TestTLS(t *testing.T) {
+	keyStore := &keystore.KeyStore{Dir: t.TempDir()}
+	certFile, keyFile, err := keyStore.LoadLocalhostCert("test.crt", "test.key")
+	must.NoError(t, err)
+	redirectPort, err := freePort()
+	must.NoError(t, err)
+
+	env := setupWithConfig(map[string]any{
+		"tls.cert_file":     certFile,
+		"tls.key_file":      keyFile,
+		"tls.redirect_port": redirectPort,
+	})
+	defer env.stop()
+	env.module.GET("/hello", func(rw http.ResponseWriter, req *http.Request, par Params) error {
+		_, err := rw.Write([]byte(req.Proto))
+		return err
+	})
+
+	certPEM, err := os.ReadFile(certFile)
+	must.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(certPEM)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots},
+			ForceAttemptHTTP2: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	t.Run("https", func(t *testing.T) {
+		resp, err := client.Get("https://" + env.module.Addr() + "/hello")
+		must.NoError(t, err)
+		defer resp.Body.Close()
+		test.Eq(t, http.StatusOK, resp.StatusCode)
+		test.Eq(t, 2, resp.ProtoMajor)
+		test.Eq(t, uint16(tls.VersionTLS13), resp.TLS.Version)
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		resp, err := client.Get("http://localhost:" + strconv.Itoa(redirectPort) + "/hello?a=b")
+		must.NoError(t, err)
+		defer resp.Body.Close()
+		test.Eq(t, http.StatusMovedPermanently, resp.StatusCode)
+		test.Eq(t, "https://localhost:"+strconv.Itoa(env.module.config.Port)+"/hello?a=b", resp.Header.Get("Location"))
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		old, _ := env.module.cert.get(nil)
+
+		// replace the files with a new certificate
+		newStore := &keystore.KeyStore{Dir: t.TempDir()}
+		newCert, newKey, err := newStore.LoadLocalhostCert("test.crt", "test.key")
+		must.NoError(t, err)
+		must.NoError(t, os.Rename(newCert, certFile))
+		must.NoError(t, os.Rename(newKey, keyFile))
+		must.NoError(t, env.module.ReloadCertificate())
+		reloaded, _ := env.module.cert.get(nil)
+		test.NotEq(t, old.Certificate[0], reloaded.Certificate[0])
+
+		// keeps the certificate if the files are invalid
+		must.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0o644))
+		must.Error(t, env.module.ReloadCertificate())
+		current, _ := env.module.cert.get(nil)
+		test.Eq(t, reloaded, current)
+	})
+}
+
+func TestTLSSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+	m := &Module{}
+	m.config.TLS.SelfSigned = true
+	m.config.TLS.KeystoreDir = dir
+	must.Error(t, m.setupTLS(service.EnvProduction))
+	test.Nil(t, m.tlsConfig)
+
+	must.NoError(t, m.setupTLS(service.EnvDevelopment))
+	test.NotNil(t, m.tlsConfig)
+	test.FileExists(t, path.Join(dir, localhostCertFile))
+	test.FileExists(t, path.Join(dir, localhostKeyFile))
+
+	// the certificate cannot sign other certificates
+	cert, _ := m.cert.get(nil)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	must.NoError(t, err)
+	test.False(t, leaf.IsCA)
+	test.Eq(t, x509.KeyUsageDigitalSignature, leaf.KeyUsage)
+}
+
+// freePort asks the OS for a free port
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func TestRedirectAddr(t *testing.T) {
+	m := &Module{}
+	m.config.Port = 8443
+	m.config.TLS.RedirectPort = 80
+	test.Eq(t, "127.0.0.1:80", m.redirectAddr())
+
+	m.config.BindExternal = true
+	test.Eq(t, "0.0.0.0:80", m.redirectAddr())
+}
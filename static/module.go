@@ -31,6 +31,7 @@ type Module struct {
 	Router *router.Module
 	Logger *logger.Module
 
+	log            logger.Logger // named "static"
 	handle404      http.HandlerFunc
 	handle500      func(rw http.ResponseWriter, req *http.Request, err error)
 	staticBasePath string
@@ -44,6 +45,7 @@ type Module struct {
 // Init this module
 func (m *Module) Init(c *service.Config) {
 	c.Setup = func() error {
+		m.log = m.Logger.Named("static")
 		m.Router.HTTPRouter.NotFound = m
 		m.staticBasePath = defaultStaticBasePath
 		m.staticDirs = defaultStaticDirs
@@ -70,7 +72,7 @@ func (m *Module) DefaultHandle404(rw http.ResponseWriter, req *http.Request) {
 
 // DefaultHandle500 default 500 handler
 func (m *Module) DefaultHandle500(rw http.ResponseWriter, req *http.Request, err error) {
-	m.Logger.ErrorCtx(req.Context(), err)
+	m.log.ErrorCtx(req.Context(), err)
 	http.Error(rw, "internal server error", http.StatusInternalServerError)
 }
 
@@ -146,7 +148,7 @@ func (m *Module) ServeAssetWithContext(rw http.ResponseWriter, req *http.Request
 
 func (m *Module) handleError(req *http.Request, rw http.ResponseWriter, err error, customErrHandler bool) {
 	if !customErrHandler {
-		m.Logger.ErrorCtx(req.Context(), err)
+		m.log.ErrorCtx(req.Context(), err)
 		rw.WriteHeader(http.StatusNotFound)
 		return
 	}
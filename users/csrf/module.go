@@ -7,17 +7,24 @@ import (
 	"github.com/go-jose/go-jose/v3"
 	"github.com/octavore/naga/service"
 
+	"github.com/octavore/nagax/config"
 	"github.com/octavore/nagax/keystore"
 	"github.com/octavore/nagax/logger"
 )
 
 const (
-	defaultKeyFile      = "session.key"
-	keyAlgorithm        = jose.RSA_OAEP
-	contentEncryption   = jose.A128GCM
-	defaultCSRFValidity = 12 * time.Hour
+	defaultKeyFile    = "session.key"
+	keyAlgorithm      = jose.RSA_OAEP
+	contentEncryption = jose.A128GCM
 )
 
+// Config for the csrf module
+type Config struct {
+	CSRF struct {
+		Validity time.Duration `json:"validity" default:"12h" validate:"min=1s" desc:"how long csrf tokens are valid for, e.g. 12h"`
+	} `json:"csrf"`
+}
+
 // KeyStore interface for retrieving keys (used for encrypting session cookie)
 type KeyStore interface {
 	LoadPrivateKey(string) ([]byte, *rsa.PrivateKey, error)
@@ -30,7 +37,9 @@ var (
 
 type Module struct {
 	Logger *logger.Module
+	Config *config.Module
 
+	config               Config
 	csrfValidityDuration time.Duration
 	keyStore             KeyStore
 	KeyFile              string
@@ -43,7 +52,11 @@ func (m *Module) Init(c *service.Config) {
 	c.Setup = func() error {
 		m.keyStore = &keystore.KeyStore{}
 		m.KeyFile = defaultKeyFile
-		m.csrfValidityDuration = defaultCSRFValidity
+		err := m.Config.ReadConfig(&m.config)
+		if err != nil {
+			return err
+		}
+		m.csrfValidityDuration = m.config.CSRF.Validity
 		return nil
 	}
 
@@ -4,8 +4,10 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/octavore/nagax/logger"
 	"github.com/octavore/nagax/router"
 	"github.com/octavore/nagax/router/httperror"
+	"github.com/octavore/nagax/router/middleware/accesslog"
 )
 
 type UserTokenKey struct{}
@@ -25,8 +27,7 @@ func (m *Module) WithAuthList(authenticators []Authenticator, next router.Handle
 			return err
 		}
 		if userToken != nil {
-			ctx := context.WithValue(req.Context(), UserTokenKey{}, *userToken)
-			req = req.WithContext(ctx)
+			req = req.WithContext(withUserToken(req.Context(), *userToken))
 		}
 		return next(rw, req, par)
 	}
@@ -43,7 +44,14 @@ func (m *Module) MustWithAuthList(authenticators []Authenticator, next router.Ha
 			return httperror.HTTPErrorCode(http.StatusUnauthorized)
 		}
 
-		ctx := context.WithValue(req.Context(), UserTokenKey{}, *userToken)
-		return next(rw, req.WithContext(ctx), par)
+		return next(rw, req.WithContext(withUserToken(req.Context(), *userToken)), par)
 	}
 }
+
+// withUserToken stores userToken in ctx, and adds it to the log fields and
+// the access log
+func withUserToken(ctx context.Context, userToken string) context.Context {
+	accesslog.SetUserID(ctx, userToken)
+	ctx = context.WithValue(ctx, UserTokenKey{}, userToken)
+	return logger.WithFields(ctx, "user_id", userToken)
+}
@@ -59,6 +59,7 @@ type Module struct {
 	KeyFile                 string
 	SessionValidityDuration time.Duration
 
+	log                     logger.Logger // named "session"
 	decryptionKey           any
 	encrypter               jose.Encrypter
 	revocationTrackDuration time.Duration
@@ -67,6 +68,7 @@ type Module struct {
 // Init implements module.Init
 func (m *Module) Init(c *service.Config) {
 	c.Setup = func() error {
+		m.log = m.Logger.Named("session")
 		m.CookieName = "session"
 		m.RevocationStore = NewInMemoryRevocationStore(defaultRevocationFlush)
 		return nil
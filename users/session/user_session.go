@@ -56,7 +56,7 @@ func (m *Module) NewSessionCookie(u *UserSession) (*http.Cookie, error) {
 func (m *Module) decodeCookieValue(value string) *UserSession {
 	obj, err := jose.ParseEncrypted(value)
 	if err != nil {
-		m.Logger.Infof("Invalid cookie value: %s.", err)
+		m.log.Infof("Invalid cookie value: %s.", err)
 		return nil
 	}
 
@@ -64,7 +64,7 @@ func (m *Module) decodeCookieValue(value string) *UserSession {
 	session := &UserSession{}
 	err = json.Unmarshal(b, session)
 	if err != nil {
-		m.Logger.Infof("Invalid cookie value: %s.", err)
+		m.log.Infof("Invalid cookie value: %s.", err)
 		return nil
 	}
 	return session
@@ -80,7 +80,7 @@ func (m *Module) getSessionFromRequest(req *http.Request) (*UserSession, error)
 		return nil, nil
 	}
 	if err != nil {
-		m.Logger.ErrorCtx(req.Context(), errors.Wrap(err))
+		m.log.ErrorCtx(req.Context(), errors.Wrap(err))
 		return nil, nil
 	}
 
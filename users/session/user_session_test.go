@@ -2,15 +2,30 @@ package session
 
 import (
 	"net/http"
+	"path/filepath"
 	"testing"
 
 	"github.com/octavore/naga/service"
 	"github.com/shoenig/test"
 )
 
+type TestModule struct {
+	*Module
+	keyDir string
+}
+
+func (m *TestModule) Init(c *service.Config) {
+	c.Setup = func() error {
+		// generate the session key in a temp dir instead of the working dir
+		m.KeyFile = filepath.Join(m.keyDir, "session.key")
+		return nil
+	}
+}
+
 func TestNewSessionCookie(t *testing.T) {
-	m, stop := service.New(&Module{}).StartForTest()
+	tm, stop := service.New(&TestModule{keyDir: t.TempDir()}).StartForTest()
 	defer stop()
+	m := tm.Module
 
 	cookie, err := m.NewSessionCookie(&UserSession{
 		ID:        "abc",
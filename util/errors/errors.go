@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"path"
 
 	goerrors "github.com/go-errors/errors"
 )
@@ -47,3 +48,18 @@ func TypeName(err error) string {
 	}
 	return fmt.Sprintf("%T", err)
 }
+
+// Location returns the package, file and line at the top of the stack of err,
+// e.g. "github.com/octavore/nagax/router/module.go|182", or "" if err was not
+// wrapped with a stack.
+func Location(err error) string {
+	var e *goerrors.Error
+	if !errors.As(err, &e) {
+		return ""
+	}
+	frames := e.StackFrames()
+	if len(frames) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s|%d", frames[0].Package, path.Base(frames[0].File), frames[0].LineNumber)
+}
@@ -37,3 +37,8 @@ func TestDoubleWrap(t *testing.T) {
 	wrappedErr2 := Wrap(wrappedErr)
 	test.Eq(t, wrappedErr, wrappedErr2)
 }
+
+func TestLocation(t *testing.T) {
+	test.Eq(t, "", Location(&TestError{}))
+	test.Eq(t, "github.com/octavore/nagax/util/errors/errors_test.go|43", Location(Wrap(&TestError{})))
+}
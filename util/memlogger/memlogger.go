@@ -2,62 +2,292 @@ package memlogger
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
 
 	"github.com/octavore/nagax/logger"
 )
 
 var _ logger.Logger = &MemoryLogger{}
 
-// MemoryLogger is an in memory logger for tests.
+// Entry is a line logged to a MemoryLogger
+type Entry struct {
+	Level   slog.Level
+	Logger  string // name of the named logger, if any
+	Message string
+	Fields  []any   // key-value pairs from the context and the KV methods
+	Errors  []error // error values in the arguments and fields
+}
+
+// String formats the entry like the Infos, Warnings and Errors slices
+func (e Entry) String() string {
+	msg := e.Message
+	if e.Logger != "" {
+		msg = e.Logger + ": " + msg
+	}
+	return logger.FormatKV(msg, e.Fields...)
+}
+
+// MemoryLogger is an in memory logger for tests. It is safe for concurrent
+// use, but the exported slices should only be read once logging goroutines
+// are done. Use Entries or the Wait and Require helpers otherwise.
 type MemoryLogger struct {
+	Debugs   []string
 	Infos    []string
 	Warnings []string
 	Errors   []string
+
+	mu      sync.Mutex
+	entries []Entry
+	changed chan struct{} // closed and replaced when an entry is added
+	t       testing.TB    // mirrors entries to t.Log if set
+}
+
+// New returns a MemoryLogger which also logs entries with t.Log, until the
+// test completes.
+func New(t testing.TB) *MemoryLogger {
+	m := &MemoryLogger{t: t}
+	t.Cleanup(func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.t = nil
+	})
+	return m
 }
 
 func (m *MemoryLogger) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Debugs = []string{}
 	m.Infos = []string{}
 	m.Warnings = []string{}
 	m.Errors = []string{}
+	m.entries = nil
 }
 
 func (m *MemoryLogger) Count() int {
-	return len(m.Infos) + len(m.Warnings) + len(m.Errors)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// Entries returns a copy of the logged entries
+func (m *MemoryLogger) Entries() []Entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Entry{}, m.entries...)
+}
+
+// add records an entry for a log call with args formatted as the message
+func (m *MemoryLogger) add(ctx context.Context, level slog.Level, msg string, args []any, kv []any) {
+	e := Entry{
+		Level:   level,
+		Logger:  logger.Name(ctx),
+		Message: msg,
+		Fields:  append(logger.Fields(ctx), kv...),
+	}
+	for _, v := range append(append([]any{}, args...), e.Fields...) {
+		if err, ok := v.(error); ok {
+			e.Errors = append(e.Errors, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, e)
+	switch {
+	case level >= slog.LevelError:
+		m.Errors = append(m.Errors, e.String())
+	case level >= slog.LevelWarn:
+		m.Warnings = append(m.Warnings, e.String())
+	case level >= slog.LevelInfo:
+		m.Infos = append(m.Infos, e.String())
+	default:
+		m.Debugs = append(m.Debugs, e.String())
+	}
+	if m.t != nil {
+		m.t.Helper()
+		m.t.Logf("[%s] %s", level, e)
+	}
+	if m.changed != nil {
+		close(m.changed)
+		m.changed = nil
+	}
+}
+
+// waitChan returns a channel which is closed when the next entry is added
+func (m *MemoryLogger) waitChan() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.changed == nil {
+		m.changed = make(chan struct{})
+	}
+	return m.changed
+}
+
+// Matcher matches log entries, see Contains, ErrorIs and HasField
+type Matcher func(e Entry) bool
+
+// Contains matches entries whose formatted line contains substr
+func Contains(substr string) Matcher {
+	return func(e Entry) bool {
+		return strings.Contains(e.String(), substr)
+	}
+}
+
+// ErrorIs matches entries with an error value which matches target with errors.Is
+func ErrorIs(target error) Matcher {
+	return func(e Entry) bool {
+		for _, err := range e.Errors {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasField matches entries with the key-value pair in their fields
+func HasField(key string, value any) Matcher {
+	return func(e Entry) bool {
+		for i := 0; i+1 < len(e.Fields); i += 2 {
+			if e.Fields[i] == key && reflect.DeepEqual(e.Fields[i+1], value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// find returns the first entry at level which matches
+func (m *MemoryLogger) find(level slog.Level, match Matcher) (Entry, bool) {
+	for _, e := range m.Entries() {
+		if e.Level == level && match(e) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// RequireError fails the test unless an error entry matches
+func (m *MemoryLogger) RequireError(t testing.TB, match Matcher) Entry {
+	t.Helper()
+	e, ok := m.find(slog.LevelError, match)
+	if !ok {
+		t.Fatalf("memlogger: no matching error in:\n%s", m.dump())
+	}
+	return e
+}
+
+// WaitForInfo waits until an info entry containing substr is logged, and
+// fails the test if there is none after timeout.
+func (m *MemoryLogger) WaitForInfo(t testing.TB, substr string, timeout time.Duration) Entry {
+	t.Helper()
+	return m.waitFor(t, slog.LevelInfo, Contains(substr), timeout)
+}
+
+func (m *MemoryLogger) waitFor(t testing.TB, level slog.Level, match Matcher, timeout time.Duration) Entry {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		changed := m.waitChan()
+		if e, ok := m.find(level, match); ok {
+			return e
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			t.Fatalf("memlogger: no matching %s entry after %s in:\n%s", level, timeout, m.dump())
+			return Entry{}
+		}
+	}
+}
+
+// AssertNoErrors marks the test as failed if any errors were logged
+func (m *MemoryLogger) AssertNoErrors(t testing.TB) {
+	t.Helper()
+	for _, e := range m.Entries() {
+		if e.Level >= slog.LevelError {
+			t.Errorf("memlogger: unexpected error: %s", e)
+		}
+	}
+}
+
+// dump formats all entries for failure messages
+func (m *MemoryLogger) dump() string {
+	lines := []string{}
+	for _, e := range m.Entries() {
+		lines = append(lines, fmt.Sprintf("  [%s] %s", e.Level, e))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *MemoryLogger) Debug(args ...any) {
+	m.add(context.Background(), slog.LevelDebug, fmt.Sprint(args...), args, nil)
+}
+
+func (m *MemoryLogger) Debugf(format string, args ...any) {
+	m.add(context.Background(), slog.LevelDebug, fmt.Sprintf(format, args...), args, nil)
+}
+
+func (m *MemoryLogger) DebugCtx(ctx context.Context, args ...any) {
+	m.add(ctx, slog.LevelDebug, fmt.Sprint(args...), args, nil)
+}
+
+func (m *MemoryLogger) DebugKV(ctx context.Context, msg string, kv ...any) {
+	m.add(ctx, slog.LevelDebug, msg, nil, kv)
 }
 
 func (m *MemoryLogger) Info(args ...any) {
-	m.Infos = append(m.Infos, fmt.Sprint(args...))
+	m.add(context.Background(), slog.LevelInfo, fmt.Sprint(args...), args, nil)
 }
 
 func (m *MemoryLogger) Infof(format string, args ...any) {
-	m.Infos = append(m.Infos, fmt.Sprintf(format, args...))
+	m.add(context.Background(), slog.LevelInfo, fmt.Sprintf(format, args...), args, nil)
 }
 
 func (m *MemoryLogger) InfoCtx(ctx context.Context, args ...any) {
-	m.Info(args...)
+	m.add(ctx, slog.LevelInfo, fmt.Sprint(args...), args, nil)
+}
+
+func (m *MemoryLogger) InfoKV(ctx context.Context, msg string, kv ...any) {
+	m.add(ctx, slog.LevelInfo, msg, nil, kv)
 }
 
 func (m *MemoryLogger) Warning(args ...any) {
-	m.Warnings = append(m.Warnings, fmt.Sprint(args...))
+	m.add(context.Background(), slog.LevelWarn, fmt.Sprint(args...), args, nil)
 }
 
 func (m *MemoryLogger) Warningf(format string, args ...any) {
-	m.Warnings = append(m.Warnings, fmt.Sprintf(format, args...))
+	m.add(context.Background(), slog.LevelWarn, fmt.Sprintf(format, args...), args, nil)
 }
 
 func (m *MemoryLogger) WarningCtx(ctx context.Context, args ...any) {
-	m.Warning(args...)
+	m.add(ctx, slog.LevelWarn, fmt.Sprint(args...), args, nil)
+}
+
+func (m *MemoryLogger) WarningKV(ctx context.Context, msg string, kv ...any) {
+	m.add(ctx, slog.LevelWarn, msg, nil, kv)
 }
 
 func (m *MemoryLogger) Error(args ...any) {
-	m.Errors = append(m.Errors, fmt.Sprint(args...))
+	m.add(context.Background(), slog.LevelError, fmt.Sprint(args...), args, nil)
 }
 
 func (m *MemoryLogger) Errorf(format string, args ...any) {
-	m.Errors = append(m.Errors, fmt.Sprintf(format, args...))
+	m.add(context.Background(), slog.LevelError, fmt.Sprintf(format, args...), args, nil)
 }
 
 func (m *MemoryLogger) ErrorCtx(ctx context.Context, args ...any) {
-	m.Error(args...)
+	m.add(ctx, slog.LevelError, fmt.Sprint(args...), args, nil)
+}
+
+func (m *MemoryLogger) ErrorKV(ctx context.Context, msg string, kv ...any) {
+	m.add(ctx, slog.LevelError, msg, nil, kv)
 }
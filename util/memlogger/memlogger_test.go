@@ -0,0 +1,84 @@
+package memlogger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+
+	"github.com/octavore/nagax/logger"
+)
+
+func TestMemoryLogger(t *testing.T) {
+	m := New(t)
+	ctx := logger.WithFields(context.Background(), "request_id", "abc")
+	err := fmt.Errorf("read: %w", io.EOF)
+	m.Infof("hello %s", "world")
+	m.ErrorCtx(ctx, err)
+	m.WarningKV(ctx, "slow", "ms", 1200)
+
+	test.Eq(t, []string{"hello world"}, m.Infos)
+	test.Eq(t, []string{"read: EOF request_id=abc"}, m.Errors)
+	test.Eq(t, []string{"slow request_id=abc ms=1200"}, m.Warnings)
+	test.Eq(t, 3, m.Count())
+
+	e := m.RequireError(t, ErrorIs(io.EOF))
+	test.Eq(t, "read: EOF", e.Message)
+	test.Eq(t, []error{err}, e.Errors)
+	m.RequireError(t, HasField("request_id", "abc"))
+	m.RequireError(t, Contains("read"))
+
+	m.Reset()
+	test.Eq(t, 0, m.Count())
+	m.AssertNoErrors(t)
+}
+
+func TestWaitForInfo(t *testing.T) {
+	m := &MemoryLogger{}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Millisecond)
+			m.Infof("tick %d", i)
+		}()
+	}
+	e := m.WaitForInfo(t, "tick 7", time.Second)
+	test.Eq(t, "tick 7", e.Message)
+	wg.Wait()
+	must.Eq(t, 10, m.Count())
+}
+
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Fatalf(format string, args ...any) { f.failed = true }
+
+func (f *fakeT) Errorf(format string, args ...any) { f.failed = true }
+
+func TestHelpersFail(t *testing.T) {
+	m := &MemoryLogger{}
+	m.Error("bad")
+
+	ft := &fakeT{}
+	m.AssertNoErrors(ft)
+	test.True(t, ft.failed)
+
+	ft = &fakeT{}
+	m.RequireError(ft, Contains("good"))
+	test.True(t, ft.failed)
+
+	ft = &fakeT{}
+	m.WaitForInfo(ft, "never", 10*time.Millisecond)
+	test.True(t, ft.failed)
+}
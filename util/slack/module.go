@@ -17,6 +17,7 @@ package slack
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/octavore/naga/service"
 	"github.com/slack-go/slack"
@@ -30,18 +31,23 @@ type Module struct {
 	Config *config.Module
 	Logger *logger.Module
 
-	LogMessages bool
-	client      slackClient
-	config      struct {
-		SlackConfig struct {
-			Channel  string `json:"channel"`
-			APIToken string `json:"api_token"`
-		} `json:"slack_internal"`
-	}
-	defaultChannel string
+	LogMessages    bool
+	client         slackClient
+	config         Config
+	defaultChannel atomic.Pointer[string] // updated on config reload
 	env            service.Environment
 }
 
+const fallbackChannel = "#activity"
+
+// Config for the slack module
+type Config struct {
+	SlackConfig struct {
+		Channel  string `json:"channel" default:"#activity" reload:"true"`
+		APIToken string `json:"api_token"`
+	} `json:"slack_internal"`
+}
+
 type slackClient interface {
 	PostMessage(channel string, params ...slack.MsgOption) (string, string, error)
 }
@@ -52,10 +58,10 @@ func (m *Module) Init(c *service.Config) {
 		if err != nil {
 			return errors.Wrap(err)
 		}
-		m.defaultChannel = m.config.SlackConfig.Channel
-		if m.defaultChannel == "" {
-			m.defaultChannel = "#activity"
-		}
+		m.setDefaultChannel(&m.config)
+		m.Config.OnReload(&m.config, func(_, new any) {
+			m.setDefaultChannel(new.(*Config))
+		})
 		m.env = c.Env()
 		return nil
 	}
@@ -70,9 +76,19 @@ func (m *Module) Init(c *service.Config) {
 	}
 }
 
+// setDefaultChannel sets the default channel from cfg, falling back to
+// #activity if it is empty
+func (m *Module) setDefaultChannel(cfg *Config) {
+	channel := cfg.SlackConfig.Channel
+	if channel == "" {
+		channel = fallbackChannel
+	}
+	m.defaultChannel.Store(&channel)
+}
+
 // Post a message to the default channel
 func (m *Module) Post(txt string, params ...slack.MsgOption) {
-	m.PostC(m.defaultChannel, txt, params...)
+	m.PostC(*m.defaultChannel.Load(), txt, params...)
 }
 
 // PostC posts a message to the given channel
@@ -81,7 +97,7 @@ func (m *Module) PostC(channel, txt string, params ...slack.MsgOption) {
 		txt = fmt.Sprintf("(%s) %s", m.env.String(), txt)
 	}
 	if m.LogMessages {
-		m.Logger.Infof("[%s] %s", m.defaultChannel, txt)
+		m.Logger.Infof("[%s] %s", *m.defaultChannel.Load(), txt)
 	}
 
 	_, _, err := m.client.PostMessage(channel,
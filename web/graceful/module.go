@@ -15,6 +15,7 @@ import (
 
 type Module struct {
 	Logger         *logger.Module
+	log            logger.Logger // named "graceful"
 	done           chan bool
 	ctx            context.Context
 	cancel         context.CancelFunc
@@ -33,6 +34,7 @@ type Module struct {
 //	}
 func (m *Module) Init(c *service.Config) {
 	c.Setup = func() error {
+		m.log = m.Logger.Named("graceful")
 		ctx := context.Background()
 		m.ctx, m.cancel = context.WithCancel(ctx)
 		m.timeoutSeconds = 30
@@ -50,7 +52,7 @@ func (m *Module) Init(c *service.Config) {
 
 		go func() {
 			sig := <-sigs
-			m.Logger.Infof("graceful: got %s signal, shutting down in %d seconds...",
+			m.log.Infof("got %s signal, shutting down in %d seconds...",
 				strings.ToUpper(sig.String()),
 				m.timeoutSeconds)
 			m.cancel()
@@ -71,10 +73,10 @@ func (m *Module) GetGracefulShutdownContext() context.Context {
 
 // Wait should be called at the top level of your app to block until the timeout completes.
 func (m *Module) Wait() {
-	m.Logger.Infof("graceful: waiting for shutdown...")
+	m.log.Infof("waiting for shutdown...")
 	if m.done != nil {
 		<-m.done
-		m.Logger.Infof("graceful: shutting down now, done channel is closed.")
+		m.log.Infof("shutting down now, done channel is closed.")
 	}
 }
 
@@ -88,7 +90,7 @@ func (m *Module) OnShutdown(shutdownFunc func(ctx context.Context)) {
 		go func() {
 			// if the m.done is closed then the app is shutdown
 			<-m.done
-			m.Logger.Infof("graceful: done channel was closed, cancelling OnShutdown ctx")
+			m.log.Infof("done channel was closed, cancelling OnShutdown ctx")
 			cancel()
 		}()
 		shutdownFunc(ctx)
@@ -98,17 +100,17 @@ func (m *Module) OnShutdown(shutdownFunc func(ctx context.Context)) {
 
 // Loop executes fn every `interval` until m.ctx is done (triggered by receiving a SIGTERM or SIGINT)
 func (m *Module) Loop(id string, interval time.Duration, fn func(time.Time)) {
-	m.Logger.Infof("graceful: [%s] starting loop...", id)
+	m.log.Infof("[%s] starting loop...", id)
 	go func() {
 		ticker := time.NewTicker(interval)
 		for {
 			select {
 			case <-m.ctx.Done():
-				m.Logger.Infof("graceful: [%s] stopping polling (shutdown)...", id)
+				m.log.Infof("[%s] stopping polling (shutdown)...", id)
 				return
 
 			case t := <-ticker.C:
-				m.Logger.Infof("graceful: [%s] tick %s", id, t)
+				m.log.Infof("[%s] tick %s", id, t)
 				fn(t)
 			}
 		}